@@ -0,0 +1,73 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code128Widths holds the bar and space widths of every Code 128 symbol,
+// starting with a bar. 103-105 are the start codes A, B and C; 106 is stop.
+var code128Widths = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes printable ASCII data. Data made only of an even number
+// of digits uses code set C, which packs two digits in each symbol; any
+// other data uses code set B.
+func Code128(data string) (*Symbol, error) {
+	if data == "" {
+		return nil, fmt.Errorf("nothing to encode")
+	}
+
+	var values []int
+	if len(data)%2 == 0 && strings.Trim(data, "0123456789") == "" {
+		values = append(values, code128StartC)
+		for i := 0; i < len(data); i += 2 {
+			values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for i := 0; i < len(data); i++ {
+			if data[i] < ' ' || data[i] > '~' {
+				return nil, fmt.Errorf("Code 128 can only encode printable ASCII characters")
+			}
+			values = append(values, int(data[i]-' '))
+		}
+	}
+
+	// checksum: start + jumlah posisi * nilai, mod 103
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, code128Stop)
+
+	var b strings.Builder
+	for _, v := range values {
+		for i, w := range code128Widths[v] {
+			bit := "1"
+			if i%2 == 1 {
+				bit = "0"
+			}
+			b.WriteString(strings.Repeat(bit, int(w-'0')))
+		}
+	}
+
+	return &Symbol{Modules: [][]bool{pattern(b.String())}, QuietZone: 10, Text: data}, nil
+}
@@ -0,0 +1,100 @@
+package barcode
+
+import (
+	"slices"
+	"testing"
+)
+
+// code128Values reads the symbol values back from the modules.
+func code128Values(t *testing.T, modules []bool) []int {
+	t.Helper()
+
+	var values []int
+	for i := 0; i < len(modules); {
+		n := 6
+		if len(modules)-i == 13 {
+			n = 7
+		}
+		widths := make([]byte, 0, n)
+		for range n {
+			start := i
+			for i < len(modules) && modules[i] == modules[start] {
+				i++
+			}
+			widths = append(widths, byte('0'+i-start))
+		}
+		v := slices.Index(code128Widths[:], string(widths))
+		if v < 0 {
+			t.Fatalf("no symbol with widths %s", widths)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestCode128Widths(t *testing.T) {
+	seen := make(map[string]bool)
+	for v, w := range code128Widths {
+		sum := 0
+		for _, r := range w {
+			sum += int(r - '0')
+		}
+		want := 11
+		if v == code128Stop {
+			want = 13
+		}
+		if sum != want {
+			t.Errorf("symbol %d (%s) is %d modules wide, want %d", v, w, sum, want)
+		}
+		if seen[w] {
+			t.Errorf("symbol %d (%s) is listed twice", v, w)
+		}
+		seen[w] = true
+	}
+}
+
+func TestCode128(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []int
+	}{
+		{name: "even digits use set C", data: "1234", want: []int{105, 12, 34, 82, 106}},
+		{name: "odd digits use set B", data: "123", want: []int{104, 17, 18, 19, 8, 106}},
+		{name: "letters use set B", data: "PJJ123C", want: []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}},
+		{name: "mixed even length uses set B", data: "A1", want: []int{104, 33, 17, 68, 106}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbol, err := Code128(tt.data)
+			if err != nil {
+				t.Fatalf("Code128() error = %v", err)
+			}
+			if got := code128Values(t, symbol.Modules[0]); !slices.Equal(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode128Pattern(t *testing.T) {
+	// start C, 12, checksum (105 + 12) % 103 = 14, stop
+	want := "11010011100" + "10110011100" + "10011001110" + "1100011101011"
+
+	symbol, err := Code128("12")
+	if err != nil {
+		t.Fatalf("Code128() error = %v", err)
+	}
+	if got := bits(symbol.Modules[0]); got != want {
+		t.Errorf("Code128() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCode128Invalid(t *testing.T) {
+	for _, data := range []string{"", "ABC\n", "café"} {
+		if _, err := Code128(data); err == nil {
+			t.Errorf("Code128(%q) = nil error, want error", data)
+		}
+	}
+}
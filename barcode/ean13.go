@@ -0,0 +1,65 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// eanL holds the left-hand, odd parity digit patterns. The even parity (G)
+// patterns are the right-hand (R) patterns reversed, and R is L inverted.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity gives, for the first digit of the code, which of the six
+// left-hand digits use even parity ('G').
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLG", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EAN13 encodes a 13 digit code with a valid check digit.
+func EAN13(code string) (*Symbol, error) {
+	if len(code) != 13 || !IsGTIN(code) {
+		return nil, fmt.Errorf("an EAN-13 barcode needs 13 digits")
+	}
+	if want := CheckDigit(code[:12]); code[12] != want {
+		return nil, fmt.Errorf("barcode %s has an invalid check digit, expected %c", code, want)
+	}
+
+	var b strings.Builder
+	b.WriteString("101")
+	parity := eanParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		l := eanL[code[i]-'0']
+		if parity[i-1] == 'G' {
+			l = reverse(invert(l))
+		}
+		b.WriteString(l)
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		b.WriteString(invert(eanL[code[i]-'0']))
+	}
+	b.WriteString("101")
+
+	return &Symbol{Modules: [][]bool{pattern(b.String())}, QuietZone: 11, Text: code}, nil
+}
+
+func invert(bits string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '0' {
+			return '1'
+		}
+		return '0'
+	}, bits)
+}
+
+func reverse(bits string) string {
+	out := []byte(bits)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
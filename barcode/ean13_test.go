@@ -0,0 +1,47 @@
+package barcode
+
+import "testing"
+
+// bits turns modules back into a string of '1' and '0'.
+func bits(modules []bool) string {
+	b := make([]byte, len(modules))
+	for i, dark := range modules {
+		b[i] = '0'
+		if dark {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+func TestEAN13(t *testing.T) {
+	// 4006381333931: first digit 4 gives the left-hand parity LGLLGG.
+	// The digit patterns are copied from the GS1 L, G and R tables.
+	want := "101" +
+		"0001101" + "0100111" + "0101111" + "0111101" + "0001001" + "0110011" +
+		"01010" +
+		"1000010" + "1000010" + "1000010" + "1110100" + "1000010" + "1100110" +
+		"101"
+
+	symbol, err := EAN13("4006381333931")
+	if err != nil {
+		t.Fatalf("EAN13() error = %v", err)
+	}
+	if len(symbol.Modules) != 1 {
+		t.Fatalf("EAN13() has %d rows, want 1", len(symbol.Modules))
+	}
+	if got := bits(symbol.Modules[0]); got != want {
+		t.Errorf("EAN13() =\n%s\nwant\n%s", got, want)
+	}
+	if symbol.QuietZone != 11 || symbol.Text != "4006381333931" {
+		t.Errorf("QuietZone, Text = %d, %q", symbol.QuietZone, symbol.Text)
+	}
+}
+
+func TestEAN13Invalid(t *testing.T) {
+	for _, code := range []string{"4006381333932", "400638133393", "40063813339311", "400638133393A"} {
+		if _, err := EAN13(code); err == nil {
+			t.Errorf("EAN13(%s) = nil error, want error", code)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package barcode checks and normalises the product codes printed under
+// barcodes.
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLength is the longest code accepted; it matches the longest Code 128
+// symbol a label printer will reasonably fit.
+const maxLength = 48
+
+// Normalize checks code and returns the form it is stored and looked up
+// in. Codes made only of digits with the length of a GTIN (EAN-8, UPC-A,
+// EAN-13 or GTIN-14) must have a valid check digit; UPC-A codes are
+// written as the equivalent EAN-13, which is how many scanners read them.
+// Any other code, such as an in-store label, is kept as it is.
+func Normalize(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", fmt.Errorf("barcode is required")
+	}
+	if len(code) > maxLength {
+		return "", fmt.Errorf("barcode %s is longer than %d characters", code, maxLength)
+	}
+	for _, r := range code {
+		if r <= ' ' || r > '~' {
+			return "", fmt.Errorf("barcode %s may only contain printable ASCII characters without spaces", code)
+		}
+	}
+
+	if !IsGTIN(code) {
+		return code, nil
+	}
+	if want := CheckDigit(code[:len(code)-1]); code[len(code)-1] != want {
+		return "", fmt.Errorf("barcode %s has an invalid check digit, expected %c", code, want)
+	}
+	if len(code) == 12 {
+		return "0" + code, nil
+	}
+	return code, nil
+}
+
+// IsGTIN reports whether code has the shape of a GTIN: 8, 12, 13 or 14
+// digits.
+func IsGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckDigit returns the GS1 check digit for the digits of a GTIN without
+// its last digit. Counting from the right, digits are weighted 3, 1, 3, ...
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
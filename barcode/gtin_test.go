@@ -0,0 +1,68 @@
+package barcode
+
+import "testing"
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{digits: "9638507", want: '4'},       // EAN-8
+		{digits: "03600029145", want: '2'},   // UPC-A
+		{digits: "400638133393", want: '1'},  // EAN-13
+		{digits: "1001234567890", want: '2'}, // GTIN-14
+		{digits: "200000000001", want: '5'},  // in-store
+		{digits: "000000000000", want: '0'},  // sum 0
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.digits, func(t *testing.T) {
+			if got := CheckDigit(tt.digits); got != tt.want {
+				t.Errorf("CheckDigit(%s) = %c, want %c", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{name: "EAN-8", code: "96385074", want: "96385074"},
+		{name: "UPC-A becomes EAN-13", code: "036000291452", want: "0036000291452"},
+		{name: "EAN-13", code: "4006381333931", want: "4006381333931"},
+		{name: "GTIN-14", code: "10012345678902", want: "10012345678902"},
+		{name: "surrounding space trimmed", code: " 4006381333931 ", want: "4006381333931"},
+		{name: "EAN-8 bad check digit", code: "96385075", wantErr: true},
+		{name: "UPC-A bad check digit", code: "036000291453", wantErr: true},
+		{name: "EAN-13 bad check digit", code: "4006381333932", wantErr: true},
+		{name: "GTIN-14 bad check digit", code: "10012345678901", wantErr: true},
+		{name: "other lengths are not checked", code: "1234567", want: "1234567"},
+		{name: "in-store code kept", code: "ABC-123", want: "ABC-123"},
+		{name: "empty", code: "  ", wantErr: true},
+		{name: "inner space", code: "AB 123", wantErr: true},
+		{name: "non-ASCII", code: "ABC-é", wantErr: true},
+		{name: "too long", code: "1234567890123456789012345678901234567890123456789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.code)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Normalize(%q) = %q, want error", tt.code, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.code, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
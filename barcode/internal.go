@@ -0,0 +1,49 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InternalRange hands out EAN-13 codes for goods without a manufacturer
+// barcode. Codes are Prefix followed by a running number and a check
+// digit. The prefix must be in a GS1 range set aside for use inside a
+// store or company (02, 04 or 20-29), so the codes can never clash with
+// real products.
+type InternalRange struct {
+	Prefix string
+}
+
+func NewInternalRange(prefix string) (*InternalRange, error) {
+	if len(prefix) < 2 || len(prefix) > 7 || strings.Trim(prefix, "0123456789") != "" {
+		return nil, fmt.Errorf("internal barcode prefix must be 2 to 7 digits")
+	}
+	if prefix[0] != '2' && !strings.HasPrefix(prefix, "02") && !strings.HasPrefix(prefix, "04") {
+		return nil, fmt.Errorf("internal barcode prefix must start with 02, 04 or 2")
+	}
+	return &InternalRange{Prefix: prefix}, nil
+}
+
+// Size returns how many codes the range holds.
+func (r *InternalRange) Size() int {
+	size := 1
+	for range 12 - len(r.Prefix) {
+		size *= 10
+	}
+	return size
+}
+
+// Code returns the code with running number seq, counting from 1.
+func (r *InternalRange) Code(seq int) (string, error) {
+	if seq < 1 || seq >= r.Size() {
+		return "", fmt.Errorf("internal barcode range %s is used up", r.Prefix)
+	}
+
+	digits := fmt.Sprintf("%s%0*d", r.Prefix, 12-len(r.Prefix), seq)
+	return digits + string(CheckDigit(digits)), nil
+}
+
+// Contains reports whether code was handed out from this range.
+func (r *InternalRange) Contains(code string) bool {
+	return len(code) == 13 && strings.HasPrefix(code, r.Prefix)
+}
@@ -0,0 +1,86 @@
+package barcode
+
+import "testing"
+
+func TestNewInternalRange(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: "20"},
+		{prefix: "29"},
+		{prefix: "02"},
+		{prefix: "04123"},
+		{prefix: "2999999"},
+		{prefix: "2", wantErr: true},
+		{prefix: "29999999", wantErr: true},
+		{prefix: "30", wantErr: true},
+		{prefix: "03", wantErr: true},
+		{prefix: "2a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			_, err := NewInternalRange(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewInternalRange(%s) error = %v, want error %v", tt.prefix, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInternalRangeCode(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		seq     int
+		want    string
+		wantErr bool
+	}{
+		{prefix: "20", seq: 1, want: "2000000000015"},
+		{prefix: "20", seq: 1234567, want: "2000012345678"},
+		{prefix: "0412345", seq: 99999, want: "0412345999992"},
+		{prefix: "0412345", seq: 100000, wantErr: true},
+		{prefix: "20", seq: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			r, err := NewInternalRange(tt.prefix)
+			if err != nil {
+				t.Fatalf("NewInternalRange() error = %v", err)
+			}
+			got, err := r.Code(tt.seq)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Code(%d) = %s, want error", tt.seq, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Code(%d) error = %v", tt.seq, err)
+			}
+			if got != tt.want {
+				t.Errorf("Code(%d) = %s, want %s", tt.seq, got, tt.want)
+			}
+			if normalized, err := Normalize(got); err != nil || normalized != got {
+				t.Errorf("Normalize(%s) = %s, %v", got, normalized, err)
+			}
+			if !r.Contains(got) {
+				t.Errorf("Contains(%s) = false", got)
+			}
+		})
+	}
+}
+
+func TestInternalRangeSize(t *testing.T) {
+	r, err := NewInternalRange("0412345")
+	if err != nil {
+		t.Fatalf("NewInternalRange() error = %v", err)
+	}
+	if got := r.Size(); got != 100000 {
+		t.Errorf("Size() = %d, want 100000", got)
+	}
+	if r.Contains("4006381333931") || r.Contains("041234500001") {
+		t.Error("Contains() = true for a code outside the range")
+	}
+}
@@ -0,0 +1,133 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// the "HELLO WORLD" 1-M example from the QR code specification
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestRSDivisor(t *testing.T) {
+	// x^7 + a^87 x^6 + a^229 x^5 + a^146 x^4 + a^149 x^3 + a^238 x^2 + a^102 x + a^21
+	want := []byte{127, 122, 154, 164, 11, 68, 117}
+	if got := rsDivisor(7); !bytes.Equal(got, want) {
+		t.Errorf("rsDivisor(7) = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeQRData(t *testing.T) {
+	// byte mode, length 1, 'A', terminator, then the EC 11 padding
+	want := []byte{0x40, 0x14, 0x10, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC}
+	if got := encodeQRData("A", 8, 16); !bytes.Equal(got, want) {
+		t.Errorf("encodeQRData() = % X, want % X", got, want)
+	}
+}
+
+func TestDrawFormat(t *testing.T) {
+	// format information for level M from the specification, bit 14 first
+	want := []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+	for mask, bits := range want {
+		t.Run(fmt.Sprint(mask), func(t *testing.T) {
+			q := newQR(1, qrVersions[0])
+			q.drawFormat(mask)
+
+			dark := func(x, y int) int {
+				if q.modules[y][x] {
+					return 1
+				}
+				return 0
+			}
+			// salinan pertama di sekitar finder kiri atas
+			first := 0
+			for i := 0; i <= 5; i++ {
+				first |= dark(8, i) << i
+			}
+			first |= dark(8, 7)<<6 | dark(8, 8)<<7 | dark(7, 8)<<8
+			for i := 9; i < 15; i++ {
+				first |= dark(14-i, 8) << i
+			}
+			// salinan kedua dibagi di finder kanan atas dan kiri bawah
+			second := 0
+			for i := 0; i < 8; i++ {
+				second |= dark(q.size-1-i, 8) << i
+			}
+			for i := 8; i < 15; i++ {
+				second |= dark(8, q.size-15+i) << i
+			}
+
+			if first != bits || second != bits {
+				t.Errorf("format bits = %015b and %015b, want %015b", first, second, bits)
+			}
+			if dark(8, q.size-8) != 1 {
+				t.Error("dark module is not set")
+			}
+		})
+	}
+}
+
+func TestDrawVersion(t *testing.T) {
+	// version information from the specification
+	want := map[int]int{7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3}
+
+	for version, bits := range want {
+		t.Run(fmt.Sprint(version), func(t *testing.T) {
+			q := newQR(version, qrVersions[version-1])
+
+			right, bottom := 0, 0
+			for i := 0; i < 18; i++ {
+				a, b := q.size-11+i%3, i/3
+				if q.modules[b][a] {
+					right |= 1 << i
+				}
+				if q.modules[a][b] {
+					bottom |= 1 << i
+				}
+			}
+			if right != bits || bottom != bits {
+				t.Errorf("version bits = %018b and %018b, want %018b", right, bottom, bits)
+			}
+		})
+	}
+}
+
+func TestQRSize(t *testing.T) {
+	tests := []struct {
+		length  int
+		size    int
+		wantErr bool
+	}{
+		{length: 1, size: 21},
+		{length: 14, size: 21},
+		{length: 15, size: 25},
+		{length: 213, size: 57},
+		{length: 214, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.length), func(t *testing.T) {
+			symbol, err := QR(string(bytes.Repeat([]byte("a"), tt.length)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("QR() = nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("QR() error = %v", err)
+			}
+			if len(symbol.Modules) != tt.size || len(symbol.Modules[0]) != tt.size {
+				t.Errorf("QR() is %dx%d, want %dx%d", len(symbol.Modules[0]), len(symbol.Modules), tt.size, tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+const (
+	TypeEAN13   = "ean13"
+	TypeCode128 = "code128"
+	TypeQR      = "qr"
+
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Types lists the symbologies that can be drawn.
+var Types = []string{TypeEAN13, TypeCode128, TypeQR}
+
+// Symbol is an encoded barcode: a grid of dark (true) and light modules.
+// Linear barcodes have a single row, which is stretched to the bar height
+// when drawn.
+type Symbol struct {
+	Modules   [][]bool
+	QuietZone int
+	// Text is printed under a linear barcode in SVG output.
+	Text string
+}
+
+// Options controls how big a symbol is drawn. Zero values use defaults.
+type Options struct {
+	// Scale is the width of one module in pixels.
+	Scale int
+	// Height is the bar height of a linear barcode in pixels.
+	Height int
+}
+
+// Limits for Options, so a request cannot ask for a huge image.
+const (
+	MaxScale  = 20
+	MaxHeight = 600
+)
+
+const (
+	defaultLinearScale = 2
+	defaultQRScale     = 4
+	defaultHeight      = 60
+)
+
+// Encode encodes data in the given symbology.
+func Encode(symbology, data string) (*Symbol, error) {
+	switch symbology {
+	case TypeEAN13:
+		return EAN13(data)
+	case TypeCode128:
+		return Code128(data)
+	case TypeQR:
+		return QR(data)
+	}
+	return nil, fmt.Errorf("type must be one of %s", strings.Join(Types, ", "))
+}
+
+func (s *Symbol) linear() bool {
+	return len(s.Modules) == 1
+}
+
+// size returns the drawing size in pixels, without any text, and the
+// module scale used.
+func (s *Symbol) size(opts Options) (width, height, scale int) {
+	scale = opts.Scale
+	if scale <= 0 {
+		scale = defaultLinearScale
+		if !s.linear() {
+			scale = defaultQRScale
+		}
+	}
+
+	width = (len(s.Modules[0]) + 2*s.QuietZone) * scale
+	if !s.linear() {
+		return width, (len(s.Modules) + 2*s.QuietZone) * scale, scale
+	}
+
+	height = opts.Height
+	if height <= 0 {
+		height = defaultHeight
+	}
+	return width, height, scale
+}
+
+// PNG draws the symbol as a black and white PNG image.
+func (s *Symbol) PNG(opts Options) ([]byte, error) {
+	width, height, scale := s.size(opts)
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.White, color.Black})
+
+	for y := 0; y < height; y++ {
+		row := s.Modules[0]
+		if !s.linear() {
+			my := y/scale - s.QuietZone
+			if my < 0 || my >= len(s.Modules) {
+				continue
+			}
+			row = s.Modules[my]
+		}
+		for x := 0; x < width; x++ {
+			mx := x/scale - s.QuietZone
+			if mx >= 0 && mx < len(row) && row[mx] {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG draws the symbol as an SVG image, with the text of a linear barcode
+// under its bars.
+func (s *Symbol) SVG(opts Options) []byte {
+	width, height, scale := s.size(opts)
+	fontSize := 0
+	if s.linear() && s.Text != "" {
+		fontSize = 7 * scale
+	}
+	total := height
+	if fontSize > 0 {
+		total += fontSize + scale
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, total, width, total)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, width, total)
+
+	// satu path, tiap deretan modul gelap jadi satu kotak
+	b.WriteString(`<path fill="#000" d="`)
+	for y, row := range s.Modules {
+		top, rowHeight := (y+s.QuietZone)*scale, scale
+		if s.linear() {
+			top, rowHeight = 0, height
+		}
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv%dh-%dz", (start+s.QuietZone)*scale, top, (x-start)*scale, rowHeight, (x-start)*scale)
+		}
+	}
+	b.WriteString(`"/>`)
+
+	if fontSize > 0 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+			width/2, height+fontSize, fontSize, html.EscapeString(s.Text))
+	}
+	b.WriteString("</svg>\n")
+
+	return []byte(b.String())
+}
+
+// pattern turns a string of '1' and '0' into modules.
+func pattern(bits string) []bool {
+	modules := make([]bool, len(bits))
+	for i := range bits {
+		modules[i] = bits[i] == '1'
+	}
+	return modules
+}
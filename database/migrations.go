@@ -0,0 +1,220 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+)
+
+// migrations are applied in order on every startup, so each statement
+// must be safe to run more than once.
+var migrations = []string{
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS allow_backorder BOOLEAN NOT NULL DEFAULT FALSE`,
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		transaction_id INT NOT NULL REFERENCES transactions(id),
+		response JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		expires_at TIMESTAMPTZ NOT NULL
+	)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cashier TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'completed'`,
+	`CREATE TABLE IF NOT EXISTS refunds (
+		id SERIAL PRIMARY KEY,
+		transaction_id INT NOT NULL REFERENCES transactions(id),
+		type TEXT NOT NULL,
+		total_amount INT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS refund_details (
+		id SERIAL PRIMARY KEY,
+		refund_id INT NOT NULL REFERENCES refunds(id),
+		transaction_detail_id INT NOT NULL REFERENCES transaction_details(id),
+		product_id INT NOT NULL,
+		quantity INT NOT NULL,
+		amount INT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_refund_details_transaction_detail_id ON refund_details (transaction_detail_id)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS payments (
+		id SERIAL PRIMARY KEY,
+		transaction_id INT NOT NULL REFERENCES transactions(id),
+		method TEXT NOT NULL,
+		amount INT NOT NULL,
+		reference TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_payments_transaction_id ON payments (transaction_id)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS gross_amount INT`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0`,
+	`UPDATE transactions SET gross_amount = total_amount WHERE gross_amount IS NULL`,
+	`ALTER TABLE transactions ALTER COLUMN gross_amount SET NOT NULL`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS gross_amount INT`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0`,
+	`UPDATE transaction_details SET gross_amount = subtotal WHERE gross_amount IS NULL`,
+	`ALTER TABLE transaction_details ALTER COLUMN gross_amount SET NOT NULL`,
+	`ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5, 2)`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5, 2)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS subtotal INT`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0`,
+	`UPDATE transactions SET subtotal = total_amount WHERE subtotal IS NULL`,
+	`ALTER TABLE transactions ALTER COLUMN subtotal SET NOT NULL`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS total_amount INT`,
+	`UPDATE transaction_details SET total_amount = subtotal WHERE total_amount IS NULL`,
+	`ALTER TABLE transaction_details ALTER COLUMN total_amount SET NOT NULL`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE refund_details ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS invoice_number TEXT`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS outlet_code TEXT NOT NULL DEFAULT ''`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_invoice_number ON transactions (invoice_number)`,
+	`CREATE TABLE IF NOT EXISTS invoice_counters (
+		outlet_code TEXT NOT NULL,
+		day DATE NOT NULL,
+		last_seq INT NOT NULL,
+		PRIMARY KEY (outlet_code, day)
+	)`,
+	`CREATE TABLE IF NOT EXISTS orders (
+		id SERIAL PRIMARY KEY,
+		label TEXT NOT NULL DEFAULT '',
+		cashier TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		transaction_id INT REFERENCES transactions(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status)`,
+	`CREATE TABLE IF NOT EXISTS order_items (
+		id SERIAL PRIMARY KEY,
+		order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+		product_id INT NOT NULL REFERENCES products(id),
+		quantity INT NOT NULL,
+		discount_type TEXT,
+		discount_value INT,
+		note TEXT NOT NULL DEFAULT ''
+	)`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS product_name TEXT`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS unit_price INT`,
+	`UPDATE transaction_details td SET product_name = COALESCE((SELECT p.name FROM products p WHERE p.id = td.product_id), '') WHERE td.product_name IS NULL`,
+	`UPDATE transaction_details SET unit_price = COALESCE(gross_amount / NULLIF(quantity, 0), 0) WHERE unit_price IS NULL`,
+	`ALTER TABLE transaction_details ALTER COLUMN product_name SET NOT NULL`,
+	`ALTER TABLE transaction_details ALTER COLUMN unit_price SET NOT NULL`,
+	`ALTER TABLE transaction_details DROP CONSTRAINT IF EXISTS transaction_details_product_id_fkey`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS rounding_amount INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS shifts (
+		id SERIAL PRIMARY KEY,
+		cashier TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		opening_float INT NOT NULL,
+		expected_cash INT,
+		counted_cash INT,
+		note TEXT NOT NULL DEFAULT '',
+		opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		closed_at TIMESTAMPTZ
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open_cashier ON shifts (cashier) WHERE status = 'open'`,
+	`CREATE TABLE IF NOT EXISTS cash_movements (
+		id SERIAL PRIMARY KEY,
+		shift_id INT NOT NULL REFERENCES shifts(id),
+		type TEXT NOT NULL,
+		amount INT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id)`,
+	`CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions (shift_id)`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id)`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS cash_amount INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS customers (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		phone TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL DEFAULT '',
+		points INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers(id)`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_earned INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS points_redeemed INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS points_reversed INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS points_restored INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS customer_points (
+		id SERIAL PRIMARY KEY,
+		customer_id INT NOT NULL REFERENCES customers(id),
+		type TEXT NOT NULL,
+		points INT NOT NULL,
+		balance_after INT NOT NULL,
+		transaction_id INT REFERENCES transactions(id),
+		refund_id INT REFERENCES refunds(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_customer_points_customer_id ON customer_points (customer_id)`,
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS credit_limit INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS receivables (
+		id SERIAL PRIMARY KEY,
+		customer_id INT NOT NULL REFERENCES customers(id),
+		transaction_id INT NOT NULL UNIQUE REFERENCES transactions(id),
+		amount INT NOT NULL,
+		paid_amount INT NOT NULL DEFAULT 0,
+		credited_amount INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_receivables_customer_id ON receivables (customer_id)`,
+	`CREATE TABLE IF NOT EXISTS repayments (
+		id SERIAL PRIMARY KEY,
+		customer_id INT NOT NULL REFERENCES customers(id),
+		amount INT NOT NULL,
+		method TEXT NOT NULL,
+		reference TEXT NOT NULL DEFAULT '',
+		shift_id INT REFERENCES shifts(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_repayments_shift_id ON repayments (shift_id)`,
+	`CREATE TABLE IF NOT EXISTS repayment_allocations (
+		id SERIAL PRIMARY KEY,
+		repayment_id INT NOT NULL REFERENCES repayments(id),
+		receivable_id INT NOT NULL REFERENCES receivables(id),
+		amount INT NOT NULL
+	)`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS credit_amount INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS client_id UUID UNIQUE`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS synced_at TIMESTAMPTZ`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS sku TEXT UNIQUE`,
+	`CREATE TABLE IF NOT EXISTS product_barcodes (
+		barcode TEXT PRIMARY KEY,
+		product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_product_barcodes_product_id ON product_barcodes (product_id)`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS sku TEXT NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS barcode_counters (
+		prefix TEXT PRIMARY KEY,
+		last_seq INT NOT NULL
+	)`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS price_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()`,
+	`CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_id ON products (name, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_price_id ON products (price, id)`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING gin (lower(name) gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_sku_lower ON products (lower(sku))`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES products(id) ON DELETE RESTRICT`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS variant_options JSONB NOT NULL DEFAULT '[]'`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_products_parent_options ON products (parent_id, options) WHERE parent_id IS NOT NULL`,
+	`ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS parent_id INT`,
+	`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS rounding_amount INT NOT NULL DEFAULT 0`,
+}
+
+func Migrate(db *sql.DB) error {
+	for _, stmt := range migrations {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Database migrated successfully")
+	return nil
+}
@@ -124,7 +124,7 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary Update category
-// @Description Update an existing category
+// @Description Update an existing category. Fields left out keep their current value; send "tax_rate": null to remove the category's own rate.
 // @Tags categories
 // @Accept json
 // @Produce json
@@ -141,15 +141,21 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var category models.Category
-	err = json.NewDecoder(r.Body).Decode(&category)
+	// body ditimpa ke data lama, jadi field yang nggak dikirim tetap
+	category, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(category)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	category.ID = id
-	err = h.service.Update(&category)
+	err = h.service.Update(category)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+}
+
+func NewCustomerHandler(service *services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// HandleCustomers - GET/POST /api/customers
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll godoc
+// @Summary Get all customers
+// @Description Retrieve all customers, optionally filtered by name or phone
+// @Tags customers
+// @Produce json
+// @Param search query string false "Part of the name or phone number"
+// @Success 200 {array} models.Customer
+// @Failure 500 {string} string "Internal error"
+// @Router /api/customers [get]
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	customers, err := h.service.GetAll(r.URL.Query().Get("search"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customers)
+}
+
+// Create godoc
+// @Summary Register customer
+// @Description Register a new customer. Phone numbers are stored digits only, with +62 written as 0.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param customer body models.Customer true "Customer data"
+// @Success 201 {object} models.Customer
+// @Failure 400 {object} models.ValidationError
+// @Router /api/customers [post]
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var customer models.Customer
+	err := json.NewDecoder(r.Body).Decode(&customer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&customer)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, customer)
+}
+
+// HandleCustomerByID - GET/PUT /api/customers/{id}, GET /api/customers/{id}/points,
+// GET /api/customers/{id}/receivables, POST /api/customers/{id}/repayments
+// and GET /api/customers/phone/{phone}
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/customers/")
+	if phone, ok := strings.CutPrefix(path, "phone/"); ok {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByPhone(w, r, phone)
+		return
+	}
+
+	idStr, action, _ := strings.Cut(path, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "points" && r.Method == http.MethodGet:
+		h.GetPoints(w, r, id)
+	case action == "receivables" && r.Method == http.MethodGet:
+		h.GetReceivables(w, r, id)
+	case action == "repayments" && r.Method == http.MethodPost:
+		h.Repay(w, r, id)
+	case action == "" || action == "points" || action == "receivables" || action == "repayments":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID godoc
+// @Summary Get customer by ID
+// @Tags customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.Customer
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/{id} [get]
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	customer, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// GetByPhone godoc
+// @Summary Find customer by phone
+// @Description Look a customer up by phone number, e.g. at the till. Spaces, dashes and a +62 prefix are ignored.
+// @Tags customers
+// @Produce json
+// @Param phone path string true "Phone number"
+// @Success 200 {object} models.Customer
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/phone/{phone} [get]
+func (h *CustomerHandler) GetByPhone(w http.ResponseWriter, r *http.Request, phone string) {
+	customer, err := h.service.GetByPhone(phone)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// Update godoc
+// @Summary Update customer
+// @Description Update a customer's name, phone, email and credit limit. Points can only change through sales and refunds.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param customer body models.Customer true "Customer data"
+// @Success 200 {object} models.Customer
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/{id} [put]
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var customer models.Customer
+	err := json.NewDecoder(r.Body).Decode(&customer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	customer.ID = id
+	err = h.service.Update(&customer)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// GetPoints godoc
+// @Summary Get customer points
+// @Description Retrieve a customer's point balance and the history of points earned, spent, taken back and given back, newest first
+// @Tags customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.PointsHistory
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/{id}/points [get]
+func (h *CustomerHandler) GetPoints(w http.ResponseWriter, r *http.Request, id int) {
+	history, err := h.service.GetPoints(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetReceivables godoc
+// @Summary Get customer receivables
+// @Description Retrieve the sales a customer paid on account and has not fully paid off yet, oldest first
+// @Tags customers
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.CustomerReceivables
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/{id}/receivables [get]
+func (h *CustomerHandler) GetReceivables(w http.ResponseWriter, r *http.Request, id int) {
+	receivables, err := h.service.GetReceivables(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receivables)
+}
+
+// Repay godoc
+// @Summary Record repayment
+// @Description Record money a customer pays towards their debt. It is applied to their oldest receivables first and cannot be more than they owe. Cash repayments count towards the cashier's shift.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param repayment body models.RepaymentRequest true "Repayment"
+// @Success 201 {object} models.Repayment
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/customers/{id}/repayments [post]
+func (h *CustomerHandler) Repay(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RepaymentRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	repayment, err := h.service.Repay(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, repayment)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"net/http"
+)
+
+// writeError maps the typed errors returned by services onto HTTP responses.
+// Anything unrecognised is treated as an internal error.
+func writeError(w http.ResponseWriter, err error) {
+	var validationErr *models.ValidationError
+	var stockErr *models.InsufficientStockError
+	var notFoundErr *models.NotFoundError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeJSON(w, http.StatusBadRequest, validationErr)
+	case errors.As(err, &stockErr):
+		writeJSON(w, http.StatusConflict, stockErr)
+	case errors.As(err, &notFoundErr):
+		http.Error(w, notFoundErr.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type OrderHandler struct {
+	service *services.OrderService
+}
+
+func NewOrderHandler(service *services.OrderService) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// HandleOrders - GET/POST /api/orders
+func (h *OrderHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll godoc
+// @Summary List orders
+// @Description Retrieve held orders, oldest first. Only open orders are listed unless another status is asked for.
+// @Tags orders
+// @Produce json
+// @Param status query string false "open (default), settled or cancelled"
+// @Success 200 {array} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 500 {string} string "Internal error"
+// @Router /api/orders [get]
+func (h *OrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Create godoc
+// @Summary Open an order
+// @Description Open a held order, optionally with a label such as a table number and some first items. Stock is not taken until the order is settled.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body models.OrderRequest true "Order data"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Router /api/orders [post]
+func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.OrderRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Create(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// HandleOrderByID - GET/PUT /api/orders/{id}, POST /api/orders/{id}/items,
+// PUT/DELETE /api/orders/{id}/items/{itemId}, POST /api/orders/{id}/cancel
+// and POST /api/orders/{id}/settle
+func (h *OrderHandler) HandleOrderByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+	idStr, action, _ := strings.Cut(path, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	if itemStr, ok := strings.CutPrefix(action, "items/"); ok {
+		itemID, err := strconv.Atoi(itemStr)
+		if err != nil {
+			http.Error(w, "Invalid order item ID", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateItem(w, r, id, itemID)
+		case http.MethodDelete:
+			h.DeleteItem(w, r, id, itemID)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "items" && r.Method == http.MethodPost:
+		h.AddItem(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, r, id)
+	case action == "settle" && r.Method == http.MethodPost:
+		h.Settle(w, r, id)
+	case action == "" || action == "items" || action == "cancel" || action == "settle":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID godoc
+// @Summary Get order by ID
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {string} string "Invalid order ID"
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id} [get]
+func (h *OrderHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	order, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// Update godoc
+// @Summary Update order
+// @Description Change the label and cashier of an open order. Items in the body are ignored; use the items endpoints instead.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param order body models.OrderRequest true "Order data"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id} [put]
+func (h *OrderHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.OrderRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Update(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// AddItem godoc
+// @Summary Add order item
+// @Description Add a line to an open order
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param item body models.OrderItemRequest true "Order item"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id}/items [post]
+func (h *OrderHandler) AddItem(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.OrderItemRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.AddItem(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// UpdateItem godoc
+// @Summary Update order item
+// @Description Replace the product, quantity, discount and note of a line on an open order
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param itemId path int true "Order item ID"
+// @Param item body models.OrderItemRequest true "Order item"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id}/items/{itemId} [put]
+func (h *OrderHandler) UpdateItem(w http.ResponseWriter, r *http.Request, id, itemID int) {
+	var req models.OrderItemRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.UpdateItem(id, itemID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// DeleteItem godoc
+// @Summary Remove order item
+// @Description Remove a line from an open order
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param itemId path int true "Order item ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id}/items/{itemId} [delete]
+func (h *OrderHandler) DeleteItem(w http.ResponseWriter, r *http.Request, id, itemID int) {
+	order, err := h.service.DeleteItem(id, itemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// Cancel godoc
+// @Summary Cancel order
+// @Description Abandon an open order. Stock is untouched because it is only taken on settlement.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/orders/{id}/cancel [post]
+func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request, id int) {
+	order, err := h.service.Cancel(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// Settle godoc
+// @Summary Settle order
+// @Description Pay for an open order. Its items go through the same checkout as /api/checkout, and the order is marked settled in the same database transaction.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param settle body models.SettleOrderRequest true "Payments and order discount"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Failure 409 {object} models.InsufficientStockError "Insufficient stock"
+// @Router /api/orders/{id}/settle [post]
+func (h *OrderHandler) Settle(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.SettleOrderRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Settle(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
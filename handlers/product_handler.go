@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"kasir-api/barcode"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
@@ -30,29 +33,77 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 
 // GetAll godoc
 // @Summary Get all products
-// @Description Retrieve all products, optionally filtered by name
+// @Description Retrieve one page of products with their category name. The body stays a plain array; the number of matching products is in X-Total-Count, and X-Next-Cursor, when present, is passed as cursor to get the next page with the same filters, sort and order.
 // @Tags products
 // @Produce json
 // @Param name query string false "Filter products by name"
+// @Param category_id query int false "Only products in this category"
+// @Param min_price query int false "Minimum price"
+// @Param max_price query int false "Maximum price"
+// @Param stock query string false "in, out or low (in stock but at or under LOW_STOCK_THRESHOLD)"
+// @Param sort query string false "name, price, stock or id (default)"
+// @Param order query string false "asc (default) or desc"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param cursor query string false "X-Next-Cursor of the previous page"
 // @Success 200 {array} models.Product
+// @Header 200 {integer} X-Total-Count "Number of products matching the filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page; absent on the last page"
+// @Failure 400 {object} models.ValidationError
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/products [get]
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	products, err := h.service.GetAll(name)
+	query := r.URL.Query()
 
+	filter := models.ProductFilter{
+		Name:   query.Get("name"),
+		Stock:  query.Get("stock"),
+		Sort:   query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+	var err error
+
+	if filter.CategoryID, err = queryInt(query, "category_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinPrice, err = queryIntPtr(query, "min_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxPrice, err = queryIntPtr(query, "max_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, err = queryInt(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetAll(filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	json.NewEncoder(w).Encode(page.Products)
 }
 
 // Create godoc
 // @Summary Create product
-// @Description Create a new product
+// @Description Create a new product. The SKU must be unique, and so must each barcode; UPC-A barcodes are stored as the equivalent EAN-13.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -92,7 +143,62 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
+// HandleProductByID - GET/PUT/DELETE /api/products/{id}, GET/POST /api/products/{id}/barcode,
+// GET/POST /api/products/{id}/variants,
+// GET /api/products/barcode/{code}, GET /api/products/search and GET /api/products/labels
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/products/")
+	if path == "search" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Search(w, r)
+		return
+	}
+	if path == "labels" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Labels(w, r)
+		return
+	}
+	if code, ok := strings.CutPrefix(path, "barcode/"); ok {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByBarcode(w, r, code)
+		return
+	}
+
+	if idStr, action, ok := strings.Cut(path, "/"); ok {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, "Invalid product ID", http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case action == "barcode" && r.Method == http.MethodGet:
+			h.BarcodeImage(w, r, id)
+		case action == "barcode" && r.Method == http.MethodPost:
+			h.AssignBarcode(w, r, id)
+		case action == "barcode":
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		case action == "variants" && r.Method == http.MethodGet:
+			h.GetVariants(w, r, id)
+		case action == "variants" && r.Method == http.MethodPost:
+			h.CreateVariant(w, r, id)
+		case action == "variants":
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
@@ -133,8 +239,280 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
+// GetByBarcode godoc
+// @Summary Find product by barcode
+// @Description Look a product up by a scanned barcode. EAN-13, UPC-A, EAN-8 and GTIN-14 codes must have a valid check digit; a UPC-A code also finds the same product stored as EAN-13.
+// @Tags products
+// @Produce json
+// @Param code path string true "Barcode"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/products/barcode/{code} [get]
+func (h *ProductHandler) GetByBarcode(w http.ResponseWriter, r *http.Request, code string) {
+	product, err := h.service.GetByBarcode(code)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		writeError(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetVariants godoc
+// @Summary List product variants
+// @Description List the variants of a product, each with its own SKU, barcodes, price, stock and the option values it was made with.
+// @Tags products
+// @Produce json
+// @Param id path int true "Parent product ID"
+// @Success 200 {array} models.Product
+// @Failure 404 {string} string "Not found"
+// @Router /api/products/{id}/variants [get]
+func (h *ProductHandler) GetVariants(w http.ResponseWriter, r *http.Request, id int) {
+	variants, err := h.service.GetVariants(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, variants)
+}
+
+// CreateVariant godoc
+// @Summary Add product variant
+// @Description Add a variant to a product with variant_options, e.g. size M in red for a t-shirt that varies by size and colour. options must pick one allowed value for every variant option, and no two variants may pick the same. The variant takes the parent's category and, unless given, its tax rate; without a name it is named after the parent and its values, e.g. "Kaos Polos - M / Merah". Once a product has variants only the variants can be sold.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Parent product ID"
+// @Param variant body models.Product true "Variant with options, sku, barcodes, price and stock"
+// @Success 201 {object} models.Product
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/products/{id}/variants [post]
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request, id int) {
+	var variant models.Product
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&variant); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.CreateVariant(id, &variant)
+	var validationErr *models.ValidationError
+	var notFoundErr *models.NotFoundError
+	if errors.As(err, &validationErr) || errors.As(err, &notFoundErr) {
+		writeError(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, variant)
+}
+
+// AssignBarcode godoc
+// @Summary Assign internal barcode
+// @Description Give a product without any barcode, such as bakery items or repacked goods, the next EAN-13 code from the store's internal range (INTERNAL_BARCODE_PREFIX). A product that already has a barcode is returned unchanged with 200.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 201 {object} models.Product
+// @Success 200 {object} models.Product
+// @Failure 404 {string} string "Not found"
+// @Router /api/products/{id}/barcode [post]
+func (h *ProductHandler) AssignBarcode(w http.ResponseWriter, r *http.Request, id int) {
+	product, assigned, err := h.service.AssignBarcode(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if assigned {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, product)
+}
+
+// BarcodeImage godoc
+// @Summary Get barcode image
+// @Description Draw one of a product's codes as a sticker-ready barcode. EAN-13 needs a 13 digit code; Code 128 and QR take any barcode or SKU. SVG output prints the code under linear barcodes.
+// @Tags products
+// @Produce png
+// @Produce image/svg+xml
+// @Param id path int true "Product ID"
+// @Param code query string false "Barcode or SKU to draw; defaults to the first barcode, then the SKU"
+// @Param type query string false "ean13, code128 or qr; defaults to ean13 for 13 digit codes, otherwise code128"
+// @Param format query string false "png (default) or svg"
+// @Param scale query int false "Pixels per module"
+// @Param height query int false "Bar height in pixels for linear barcodes"
+// @Success 200 {file} file "Barcode image"
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/products/{id}/barcode [get]
+func (h *ProductHandler) BarcodeImage(w http.ResponseWriter, r *http.Request, id int) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = barcode.FormatPNG
+	}
+	if format != barcode.FormatPNG && format != barcode.FormatSVG {
+		http.Error(w, "format must be png or svg", http.StatusBadRequest)
+		return
+	}
+
+	symbology := query.Get("type")
+	if symbology != "" && symbology != barcode.TypeEAN13 && symbology != barcode.TypeCode128 && symbology != barcode.TypeQR {
+		http.Error(w, "type must be ean13, code128 or qr", http.StatusBadRequest)
+		return
+	}
+
+	scale, err := queryInt(query, "scale")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if scale < 0 || scale > barcode.MaxScale {
+		http.Error(w, fmt.Sprintf("scale must be between 1 and %d", barcode.MaxScale), http.StatusBadRequest)
+		return
+	}
+	height, err := queryInt(query, "height")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if height < 0 || height > barcode.MaxHeight {
+		http.Error(w, fmt.Sprintf("height must be between 1 and %d", barcode.MaxHeight), http.StatusBadRequest)
+		return
+	}
+
+	body, err := h.service.BarcodeImage(id, query.Get("code"), symbology, format, barcode.Options{Scale: scale, Height: height})
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		writeError(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if format == barcode.FormatSVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.Write(body)
+}
+
+// Search godoc
+// @Summary Search products
+// @Description Find products by name, SKU, barcode or category, best match first. Small typos and words in any order are tolerated, e.g. "indomi goreng" finds "Indomie Goreng". With mode=autocomplete only the top suggestions are returned, with id, name, sku, price and stock.
+// @Tags products
+// @Produce json
+// @Param q query string true "What the cashier typed or scanned"
+// @Param mode query string false "autocomplete for search-as-you-type suggestions"
+// @Param limit query int false "Maximum results (default 20, max 100; autocomplete default 8, max 20)"
+// @Success 200 {array} models.ProductMatch
+// @Success 200 {array} models.ProductSuggestion "With mode=autocomplete"
+// @Failure 400 {object} models.ValidationError
+// @Router /api/products/search [get]
+func (h *ProductHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := queryInt(query, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var results any
+	switch query.Get("mode") {
+	case "":
+		results, err = h.service.Search(query.Get("q"), limit)
+	case "autocomplete":
+		results, err = h.service.Autocomplete(query.Get("q"), limit)
+	default:
+		http.Error(w, "mode must be autocomplete or left out", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// Labels godoc
+// @Summary Print shelf price labels
+// @Description Render price labels with name, price and barcode as an A4 PDF, ready to print and cut. Pick the products with exactly one of ids, category_id or changed_since; changed_since picks every product whose price changed on or after that date.
+// @Tags products
+// @Produce application/pdf
+// @Param ids query string false "Comma separated product IDs, e.g. 1,2,3"
+// @Param category_id query int false "Print every product in this category"
+// @Param changed_since query string false "Print products whose price changed since this date (YYYY-MM-DD)"
+// @Param layout query string false "Labels per sheet as columns x rows, e.g. 3x8 (default), 2x5 or 4x10"
+// @Param copies query int false "Labels per product (default 1)"
+// @Success 200 {file} file "PDF label sheets"
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Category not found"
+// @Router /api/products/labels [get]
+func (h *ProductHandler) Labels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var filter models.LabelFilter
+	if ids := query.Get("ids"); ids != "" {
+		for _, s := range strings.Split(ids, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				http.Error(w, "ids must be a comma separated list of product IDs", http.StatusBadRequest)
+				return
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+	}
+
+	var err error
+	filter.CategoryID, err = queryInt(query, "category_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.ChangedSince, err = queryDate(query, "changed_since")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	copies, err := queryInt(query, "copies")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := h.service.Labels(filter, query.Get("layout"), copies)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="labels.pdf"`)
+	w.Write(body)
+}
+
 // Update godoc
 // @Summary Update product
+// @Description Update a product. Fields left out keep their current value. Send "sku": "" to remove the SKU, "barcodes": [] to remove the barcodes and "tax_rate": null to remove the product's own rate.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -150,15 +528,23 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
+	// body ditimpa ke data lama, jadi field yang nggak dikirim tetap
+	product, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	product.CategoryName = ""
+	product.Variants = nil
+
+	err = json.NewDecoder(r.Body).Decode(product)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	product.ID = id
-	err = h.service.Update(&product)
+	err = h.service.Update(product)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -170,6 +556,7 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary Delete product
+// @Description Delete a product. A product with variants cannot be deleted until its variants are, nor one that sales or orders still refer to.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
@@ -186,7 +573,7 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err = h.service.Delete(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
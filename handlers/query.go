@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// queryInt parses an optional integer query parameter, returning 0 when absent.
+func queryInt(query url.Values, key string) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+
+	return n, nil
+}
+
+// queryIntPtr is like queryInt but returns nil when the parameter is absent,
+// so that an explicit 0 can be told apart from "not given".
+func queryIntPtr(query url.Values, key string) (*int, error) {
+	if query.Get(key) == "" {
+		return nil, nil
+	}
+
+	n, err := queryInt(query, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// queryDate validates an optional YYYY-MM-DD query parameter.
+func queryDate(query url.Values, key string) (string, error) {
+	value := query.Get(key)
+	if value == "" {
+		return "", nil
+	}
+
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("%s must be in YYYY-MM-DD format", key)
+	}
+
+	return value, nil
+}
@@ -44,6 +44,14 @@ func (h *ReportHandler) routeReport(w http.ResponseWriter, r *http.Request) {
 		h.GetTodayReport(w, r)
 		return
 
+	case "/api/report/receivables":
+		h.GetReceivablesAging(w, r)
+		return
+
+	case "/api/report/products":
+		h.GetProductSales(w, r)
+		return
+
 	case "/api/report":
 		startDate := r.URL.Query().Get("start_date")
 		endDate := r.URL.Query().Get("end_date")
@@ -105,3 +113,60 @@ func (h *ReportHandler) GetDateRangeReport(
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(report)
 }
+
+// GetProductSales godoc
+// @Summary Get product sales report
+// @Description Returns quantity sold and revenue per product between start_date and end_date, net of refunds, best seller first. With group_by=parent, variants are rolled up into their parent product.
+// @Tags report
+// @Produce json
+// @Param start_date query string true "Start date in YYYY-MM-DD format"
+// @Param end_date query string true "End date in YYYY-MM-DD format"
+// @Param group_by query string false "product (default) or parent"
+// @Success 200 {object} models.ProductSalesReport
+// @Failure 400 {object} models.ValidationError
+// @Failure 500 {object} map[string]string
+// @Router /api/report/products [get]
+func (h *ReportHandler) GetProductSales(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	startDate, err := queryDate(query, "start_date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endDate, err := queryDate(query, "end_date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if startDate == "" || endDate == "" {
+		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetProductSales(startDate, endDate, query.Get("group_by"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetReceivablesAging godoc
+// @Summary Get receivables aging
+// @Description Returns what each customer still owes from sales paid on account, split into 0-30, 31-60 and over 60 days old
+// @Tags report
+// @Produce json
+// @Success 200 {object} models.AgingReport
+// @Failure 500 {object} map[string]string
+// @Router /api/report/receivables [get]
+func (h *ReportHandler) GetReceivablesAging(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetReceivablesAging()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ShiftHandler struct {
+	service *services.ShiftService
+}
+
+func NewShiftHandler(service *services.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// HandleShifts - GET/POST /api/shifts
+func (h *ShiftHandler) HandleShifts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll godoc
+// @Summary List shifts
+// @Description Retrieve cashier shifts, newest first. Only open shifts are listed unless another status is asked for.
+// @Tags shifts
+// @Produce json
+// @Param status query string false "open (default) or closed"
+// @Success 200 {array} models.Shift
+// @Failure 400 {object} models.ValidationError
+// @Failure 500 {string} string "Internal error"
+// @Router /api/shifts [get]
+func (h *ShiftHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	shifts, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shifts)
+}
+
+// Open godoc
+// @Summary Open a shift
+// @Description Start a cashier shift with the cash float in the drawer. Checkouts by that cashier are booked on the shift until it is closed.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param shift body models.OpenShiftRequest true "Cashier and opening float"
+// @Success 201 {object} models.Shift
+// @Failure 400 {object} models.ValidationError
+// @Router /api/shifts [post]
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.OpenShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.Open(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, shift)
+}
+
+// HandleShiftByID - GET /api/shifts/{id}, POST /api/shifts/{id}/cash-movements
+// and POST /api/shifts/{id}/close
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/shifts/")
+	idStr, action, _ := strings.Cut(path, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid shift ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "cash-movements" && r.Method == http.MethodPost:
+		h.AddCashMovement(w, r, id)
+	case action == "close" && r.Method == http.MethodPost:
+		h.Close(w, r, id)
+	case action == "" || action == "cash-movements" || action == "close":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID godoc
+// @Summary Get shift by ID
+// @Description Retrieve a shift with its cash totals, expected drawer amount and cash movements
+// @Tags shifts
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Success 200 {object} models.Shift
+// @Failure 400 {string} string "Invalid shift ID"
+// @Failure 404 {string} string "Not found"
+// @Router /api/shifts/{id} [get]
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	shift, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// AddCashMovement godoc
+// @Summary Record cash in or out
+// @Description Record cash put into or taken out of the drawer outside of a sale, such as petty cash or a bank drop
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param movement body models.CashMovementRequest true "Cash movement"
+// @Success 201 {object} models.CashMovement
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/shifts/{id}/cash-movements [post]
+func (h *ShiftHandler) AddCashMovement(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CashMovementRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	movement, err := h.service.AddCashMovement(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, movement)
+}
+
+// Close godoc
+// @Summary Close a shift
+// @Description Close a shift with the cash counted in the drawer and get the expected-versus-counted variance
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param close body models.CloseShiftRequest true "Counted cash"
+// @Success 200 {object} models.Shift
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/shifts/{id}/close [post]
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CloseShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.Close(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
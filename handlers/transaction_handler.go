@@ -2,11 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"kasir-api/models"
+	"kasir-api/receipt"
 	"kasir-api/services"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
+const maxIdempotencyKeyLength = 255
+
 // TransactionHandler handles checkout requests
 type TransactionHandler struct {
 	service *services.TransactionService
@@ -18,13 +26,22 @@ func NewTransactionHandler(service *services.TransactionService) *TransactionHan
 
 // HandleCheckout godoc
 // @Summary Create a new transaction (checkout)
-// @Description Process a list of items and create a transaction
+// @Description Process a list of items and payments and create a transaction.
+// @Description Payments may be split across methods; only cash can be overpaid, and the difference is returned as change.
+// @Description Items and the whole order may carry a percent or fixed discount; order discounts are spread over the lines.
+// @Description Tax is worked out per line after discounts, using the product, category or store rate.
+// @Description The sale is booked on shift_id, or on the cashier's open shift when no shift is given.
+// @Description A customer may be attached to earn loyalty points, and points can be spent with the "points" payment method.
+// @Description The "on_account" method books the amount as debt for the customer; it is rejected when it would take them over their credit limit.
 // @Tags transaction
 // @Accept  json
 // @Produce  json
+// @Param Idempotency-Key header string false "Replays the stored result when a request is retried with the same key"
 // @Param checkout body models.CheckoutRequest true "Checkout request body"
 // @Success 200 {object} models.Transaction
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} models.ValidationError "Invalid request body"
+// @Failure 409 {object} models.InsufficientStockError "Insufficient stock"
+// @Failure 422 {object} map[string]string "Idempotency key reused with a different body"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/checkout [post]
 func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
@@ -44,12 +61,329 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transaction, err := h.service.Checkout(req.Items)
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+		return
+	}
+
+	transaction, replayed, err := h.service.Checkout(req, idempotencyKey)
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// HandleSync godoc
+// @Summary Sync offline transactions
+// @Description Upload sales made while a till was offline. Each sale carries the client_id (a UUID) the till gave it and the created_at time it was made, which reports use as the sale time.
+// @Description Every sale goes through checkout on its own and gets its own result: accepted, duplicate when the client_id was already uploaded, or rejected with the reason (unknown product, insufficient stock, invalid payments, ...).
+// @Description If the request fails as a whole it can be sent again safely; sales already accepted come back as duplicates.
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param sync body models.SyncRequest true "Offline sales"
+// @Success 200 {object} models.SyncResponse
+// @Failure 400 {object} models.ValidationError
+// @Failure 500 {string} string "Internal error"
+// @Router /api/transactions/sync [post]
+func (h *TransactionHandler) HandleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.SyncRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.service.Sync(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// HandleQuote godoc
+// @Summary Quote a checkout
+// @Description Price a checkout request without saving anything or touching stock, to show the running total before the customer pays.
+// @Description Stock shortages, backorders and payments that do not cover the total come back as warnings; unknown products and invalid discounts are rejected like a checkout.
+// @Tags transaction
+// @Accept  json
+// @Produce  json
+// @Param checkout body models.CheckoutRequest true "Checkout request body; payments are optional"
+// @Success 200 {object} models.CheckoutQuote
+// @Failure 400 {object} models.ValidationError "Invalid request body"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/checkout/quote [post]
+func (h *TransactionHandler) HandleQuote(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Quote(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TransactionHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req models.CheckoutRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.Quote(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// HandleTransactions - GET /api/transactions
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll godoc
+// @Summary List transactions
+// @Description Retrieve past transactions, newest first, with pagination and filters
+// @Tags transaction
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param start_date query string false "Only transactions on or after this date (YYYY-MM-DD)"
+// @Param end_date query string false "Only transactions on or before this date (YYYY-MM-DD)"
+// @Param min_amount query int false "Minimum total amount"
+// @Param max_amount query int false "Maximum total amount"
+// @Param product_id query int false "Only transactions containing this product"
+// @Param payment_method query string false "Only transactions paid (partly) with this method"
+// @Param invoice_number query string false "Search by (part of) the invoice number"
+// @Success 200 {object} models.TransactionList
+// @Failure 400 {string} string "Invalid query parameter"
+// @Failure 500 {string} string "Internal error"
+// @Router /api/transactions [get]
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var filter models.TransactionFilter
+	var err error
+
+	if filter.Page, err = queryInt(query, "page"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, err = queryInt(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.ProductID, err = queryInt(query, "product_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.PaymentMethod = query.Get("payment_method")
+	filter.InvoiceNumber = query.Get("invoice_number")
+	if filter.MinAmount, err = queryIntPtr(query, "min_amount"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxAmount, err = queryIntPtr(query, "max_amount"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.StartDate, err = queryDate(query, "start_date"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.EndDate, err = queryDate(query, "end_date"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, err := h.service.GetAll(filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
+
+// HandleTransactionByID - GET /api/transactions/{id}, GET /api/transactions/{id}/receipt,
+// POST /api/transactions/{id}/void and POST /api/transactions/{id}/refunds
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/transactions/")
+	idStr, action, _ := strings.Cut(path, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "receipt" && r.Method == http.MethodGet:
+		h.Receipt(w, r, id)
+	case action == "void" && r.Method == http.MethodPost:
+		h.Void(w, r, id)
+	case action == "refunds" && r.Method == http.MethodPost:
+		h.Refund(w, r, id)
+	case action == "" || action == "receipt" || action == "void" || action == "refunds":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetByID godoc
+// @Summary Get transaction by ID
+// @Description Retrieve a transaction with its detail lines and refunds
+// @Tags transaction
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {string} string "Invalid transaction ID"
+// @Failure 404 {string} string "Not found"
+// @Router /api/transactions/{id} [get]
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(transaction)
 }
+
+// Receipt godoc
+// @Summary Print transaction receipt
+// @Description Render a stored transaction as a thermal printer receipt, either as fixed-width text or as a raw ESC/POS byte stream
+// @Tags transaction
+// @Produce plain
+// @Produce octet-stream
+// @Param id path int true "Transaction ID"
+// @Param format query string false "text (default) or escpos"
+// @Param width query int false "Characters per line: 32 for 58 mm, 48 for 80 mm paper"
+// @Success 200 {string} string "Receipt"
+// @Failure 400 {string} string "Invalid query parameter"
+// @Failure 404 {string} string "Not found"
+// @Router /api/transactions/{id}/receipt [get]
+func (h *TransactionHandler) Receipt(w http.ResponseWriter, r *http.Request, id int) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receipt.FormatText
+	}
+	if format != receipt.FormatText && format != receipt.FormatESCPOS {
+		http.Error(w, "format must be text or escpos", http.StatusBadRequest)
+		return
+	}
+
+	width, err := queryInt(r.URL.Query(), "width")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if width != 0 && (width < receipt.MinWidth || width > receipt.MaxWidth) {
+		http.Error(w, fmt.Sprintf("width must be between %d and %d", receipt.MinWidth, receipt.MaxWidth), http.StatusBadRequest)
+		return
+	}
+
+	body, err := h.service.Receipt(id, format, width)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == receipt.FormatESCPOS {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=receipt-%d.bin", id))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Write(body)
+}
+
+// Void godoc
+// @Summary Void transaction
+// @Description Cancel a whole transaction and return all of its stock
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param void body models.VoidRequest false "Void reason"
+// @Success 201 {object} models.Refund
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/transactions/{id}/void [post]
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.VoidRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	refund, err := h.service.Void(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// Refund godoc
+// @Summary Refund transaction items
+// @Description Refund some quantity of one or more transaction lines and return the stock. For sales paid on account the refund first comes off what the customer still owes.
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param refund body models.RefundRequest true "Lines and quantities to refund"
+// @Success 201 {object} models.Refund
+// @Failure 400 {object} models.ValidationError
+// @Failure 404 {string} string "Not found"
+// @Router /api/transactions/{id}/refunds [post]
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RefundRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	refund, err := h.service.Refund(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, refund)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"kasir-api/database"
+	"kasir-api/invoice"
+	"kasir-api/loyalty"
+	"kasir-api/models"
+	"kasir-api/pricing"
+	"kasir-api/receipt"
+	"kasir-api/repositories"
+	"kasir-api/services"
+)
+
+// testServer serves the checkout endpoints with the real service and
+// repositories on the database in TEST_DATABASE_URL. Tests that need it are
+// skipped when it is not set.
+func testServer(t *testing.T) (*httptest.Server, *sql.DB) {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.InitDB(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// tanpa pajak dan pembulatan, tapi jumlah bayar tetap diambil dari quote
+	calculator := pricing.NewCalculator(pricing.Calculator{MaxDiscountPercent: 100, CashRounding: pricing.RoundingNone})
+	numberer, err := invoice.NewNumberer("", "TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := loyalty.NewProgram(loyalty.Program{PointValue: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := services.NewTransactionService(
+		repositories.NewTransactionRepository(db, calculator, numberer, program, false),
+		repositories.NewRefundRepository(db, false),
+		repositories.NewIdempotencyRepository(db),
+		time.Hour,
+		receipt.NewPrinter(receipt.Store{Name: "Test"}, 32),
+		services.CheckoutLimits{},
+	)
+	handler := NewTransactionHandler(service)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/checkout", handler.HandleCheckout)
+	mux.HandleFunc("/api/checkout/quote", handler.HandleQuote)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, db
+}
+
+func postJSON(url string, body any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.Post(url, "application/json", bytes.NewReader(b))
+}
+
+func TestCheckoutConcurrentStock(t *testing.T) {
+	server, db := testServer(t)
+
+	const (
+		stock     = 20
+		checkouts = 50
+	)
+
+	name := fmt.Sprintf("concurrency test %d", time.Now().UnixNano())
+	var categoryID, productID int
+	err := db.QueryRow("INSERT INTO categories (name) VALUES ($1) RETURNING id", name).Scan(&categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(
+		"INSERT INTO products (name, price, stock, category_id, tax_rate) VALUES ($1, 1500, $2, $3, 0) RETURNING id",
+		name, stock, categoryID,
+	).Scan(&productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: productID, Quantity: 1}}}
+	res, err := postJSON(server.URL+"/api/checkout/quote", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quote models.CheckoutQuote
+	err = json.NewDecoder(res.Body).Decode(&quote)
+	res.Body.Close()
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("quote: status %d, error %v", res.StatusCode, err)
+	}
+	req.Payments = []models.CheckoutPayment{{
+		Method: models.PaymentMethodCash,
+		Amount: quote.Transaction.TotalAmount + quote.Transaction.RoundingAmount,
+	}}
+
+	var (
+		wg                  sync.WaitGroup
+		mu                  sync.Mutex
+		succeeded, rejected int
+		unexpected          []string
+	)
+	for range checkouts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := postJSON(server.URL+"/api/checkout", req)
+			if err != nil {
+				mu.Lock()
+				unexpected = append(unexpected, err.Error())
+				mu.Unlock()
+				return
+			}
+			defer res.Body.Close()
+
+			var stockErr models.InsufficientStockError
+			if res.StatusCode == http.StatusConflict {
+				if err := json.NewDecoder(res.Body).Decode(&stockErr); err != nil || len(stockErr.Items) == 0 {
+					t.Errorf("409 without stock shortages: %v", err)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch res.StatusCode {
+			case http.StatusOK:
+				succeeded++
+			case http.StatusConflict:
+				rejected++
+			default:
+				unexpected = append(unexpected, res.Status)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, status := range unexpected {
+		t.Errorf("unexpected checkout result: %s", status)
+	}
+	if succeeded != stock {
+		t.Errorf("succeeded = %d, want %d", succeeded, stock)
+	}
+	if rejected != checkouts-stock {
+		t.Errorf("rejected = %d, want %d", rejected, checkouts-stock)
+	}
+
+	var left int
+	if err := db.QueryRow("SELECT stock FROM products WHERE id = $1", productID).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("stock after checkouts = %d, want 0", left)
+	}
+}
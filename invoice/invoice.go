@@ -0,0 +1,91 @@
+// Package invoice formats human-readable invoice numbers such as
+// INV/20261017/0042 from a configurable template.
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DefaultFormat is used when no template is configured.
+const DefaultFormat = "INV/{date}/{seq:4}"
+
+// placeholder matches {date}, {yyyy}, {yy}, {mm}, {dd}, {outlet}, {seq} and
+// {seq:N}, where N is the minimum number of digits.
+var placeholder = regexp.MustCompile(`\{(date|yyyy|yy|mm|dd|outlet|seq)(?::(\d+))?\}`)
+
+type Numberer struct {
+	Format        string
+	DefaultOutlet string
+	perOutlet     bool
+}
+
+// NewNumberer checks format and returns a Numberer for it. Sequences
+// restart every day, so the format must contain {seq} and the full date,
+// otherwise two invoices would get the same number.
+func NewNumberer(format, defaultOutlet string) (*Numberer, error) {
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	has := make(map[string]bool)
+	for _, m := range placeholder.FindAllStringSubmatch(format, -1) {
+		has[m[1]] = true
+	}
+	if !has["seq"] {
+		return nil, errors.New("invoice format must contain {seq}")
+	}
+	if !has["date"] && !(has["dd"] && has["mm"] && (has["yyyy"] || has["yy"])) {
+		return nil, errors.New("invoice format must contain {date} or {yyyy}/{yy}, {mm} and {dd}")
+	}
+
+	return &Numberer{Format: format, DefaultOutlet: defaultOutlet, perOutlet: has["outlet"]}, nil
+}
+
+// Outlet returns the outlet a checkout is numbered under.
+func (n *Numberer) Outlet(outlet string) string {
+	if outlet == "" {
+		return n.DefaultOutlet
+	}
+	return outlet
+}
+
+// Sequence returns the key of the counter that numbers invoices of outlet.
+// Outlets only get their own counter when the format prints the outlet;
+// otherwise they would hand out the same numbers.
+func (n *Numberer) Sequence(outlet string) string {
+	if n.perOutlet {
+		return outlet
+	}
+	return ""
+}
+
+// Number renders the invoice number for the seq-th transaction of outlet on day.
+func (n *Numberer) Number(outlet string, day time.Time, seq int) string {
+	return placeholder.ReplaceAllStringFunc(n.Format, func(token string) string {
+		m := placeholder.FindStringSubmatch(token)
+		switch m[1] {
+		case "date":
+			return day.Format("20060102")
+		case "yyyy":
+			return day.Format("2006")
+		case "yy":
+			return day.Format("06")
+		case "mm":
+			return day.Format("01")
+		case "dd":
+			return day.Format("02")
+		case "outlet":
+			return outlet
+		}
+
+		digits := 1
+		if m[2] != "" {
+			digits, _ = strconv.Atoi(m[2])
+		}
+		return fmt.Sprintf("%0*d", digits, seq)
+	})
+}
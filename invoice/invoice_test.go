@@ -0,0 +1,103 @@
+package invoice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewNumberer(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: ""},
+		{format: "INV/{date}/{seq:4}"},
+		{format: "{outlet}-{yyyy}{mm}{dd}-{seq}"},
+		{format: "{yy}.{mm}.{dd}/{seq:6}"},
+		{format: "INV/{date}", wantErr: true},
+		{format: "INV/{seq:4}", wantErr: true},
+		{format: "INV/{yyyy}{mm}/{seq}", wantErr: true},
+		{format: "INV/{mm}{dd}/{seq}", wantErr: true},
+		{format: "INV/{day}/{seq}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			n, err := NewNumberer(tt.format, "MAIN")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewNumberer(%q) = %+v, want error", tt.format, n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewNumberer(%q) error = %v", tt.format, err)
+			}
+			if tt.format == "" && n.Format != DefaultFormat {
+				t.Errorf("Format = %q, want %q", n.Format, DefaultFormat)
+			}
+		})
+	}
+}
+
+func TestNumber(t *testing.T) {
+	day := time.Date(2026, 3, 7, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format string
+		outlet string
+		seq    int
+		want   string
+	}{
+		{name: "default", format: "", seq: 42, want: "INV/20260307/0042"},
+		{name: "no padding", format: "INV/{date}/{seq}", seq: 7, want: "INV/20260307/7"},
+		{name: "longer than the padding", format: "INV/{date}/{seq:2}", seq: 1234, want: "INV/20260307/1234"},
+		{name: "date parts", format: "{dd}-{mm}-{yyyy}/{yy}/{seq:3}", seq: 5, want: "07-03-2026/26/005"},
+		{name: "outlet", format: "{outlet}/{date}/{seq:4}", outlet: "JKT01", seq: 1, want: "JKT01/20260307/0001"},
+		{name: "other braces kept", format: "{x}/{date}/{seq}", seq: 1, want: "{x}/20260307/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNumberer(tt.format, "MAIN")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := n.Number(tt.outlet, day, tt.seq); got != tt.want {
+				t.Errorf("Number() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutletAndSequence(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		outlet       string
+		wantOutlet   string
+		wantSequence string
+	}{
+		{name: "default outlet", format: DefaultFormat, outlet: "", wantOutlet: "MAIN", wantSequence: ""},
+		{name: "shared counter without outlet in format", format: DefaultFormat, outlet: "JKT01", wantOutlet: "JKT01", wantSequence: ""},
+		{name: "own counter per outlet", format: "{outlet}/{date}/{seq}", outlet: "JKT01", wantOutlet: "JKT01", wantSequence: "JKT01"},
+		{name: "default outlet has its own counter", format: "{outlet}/{date}/{seq}", outlet: "", wantOutlet: "MAIN", wantSequence: "MAIN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNumberer(tt.format, "MAIN")
+			if err != nil {
+				t.Fatal(err)
+			}
+			outlet := n.Outlet(tt.outlet)
+			if outlet != tt.wantOutlet {
+				t.Errorf("Outlet(%q) = %q, want %q", tt.outlet, outlet, tt.wantOutlet)
+			}
+			if got := n.Sequence(outlet); got != tt.wantSequence {
+				t.Errorf("Sequence(%q) = %q, want %q", outlet, got, tt.wantSequence)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package label lays out shelf price labels on A4 sheets and writes them as
+// a PDF, ready to print and cut.
+package label
+
+import (
+	"fmt"
+	"kasir-api/barcode"
+	"kasir-api/models"
+	"kasir-api/rupiah"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLayout = "3x8"
+
+	MaxColumns = 6
+	MaxRows    = 15
+)
+
+// Sheet margins and the gap between labels, in points.
+const (
+	margin  = 28.35 // 10 mm
+	gap     = 5.67  // 2 mm
+	padding = 6
+)
+
+// Layout is how many labels fit across and down one sheet.
+type Layout struct {
+	Columns int
+	Rows    int
+}
+
+// ParseLayout reads a layout written as columns x rows, e.g. "3x8".
+func ParseLayout(s string) (Layout, error) {
+	cols, rows, ok := strings.Cut(strings.ToLower(s), "x")
+	c, errC := strconv.Atoi(cols)
+	r, errR := strconv.Atoi(rows)
+	if !ok || errC != nil || errR != nil || c < 1 || c > MaxColumns || r < 1 || r > MaxRows {
+		return Layout{}, fmt.Errorf("layout must be columns x rows, e.g. %s, with at most %d columns and %d rows", DefaultLayout, MaxColumns, MaxRows)
+	}
+	return Layout{Columns: c, Rows: r}, nil
+}
+
+// PDF writes one label per product, copies times each, filling the sheets
+// left to right and top to bottom.
+func PDF(products []models.Product, layout Layout, copies int) ([]byte, error) {
+	doc := &pdf{}
+	width := (pageWidth - 2*margin - float64(layout.Columns-1)*gap) / float64(layout.Columns)
+	height := (pageHeight - 2*margin - float64(layout.Rows-1)*gap) / float64(layout.Rows)
+	perPage := layout.Columns * layout.Rows
+
+	var pg *page
+	n := 0
+	for _, p := range products {
+		for range max(copies, 1) {
+			if n%perPage == 0 {
+				pg = doc.newPage()
+			}
+			cell := n % perPage
+			x := margin + float64(cell%layout.Columns)*(width+gap)
+			y := margin + float64(cell/layout.Columns)*(height+gap)
+			drawLabel(pg, p, x, y, width, height)
+			n++
+		}
+	}
+	if n == 0 {
+		doc.newPage()
+	}
+
+	return doc.bytes()
+}
+
+// drawLabel draws one label: the product name at the top, the price under
+// it and the barcode along the bottom when there is room for it.
+func drawLabel(pg *page, p models.Product, x, y, w, h float64) {
+	pg.strokeRect(x, y, w, h)
+	inner := w - 2*padding
+
+	nameSize := min(11, h*0.12)
+	priceSize := min(24, h*0.24, inner/7)
+	top := y + padding
+
+	// nama maksimal dua baris, sisanya dipotong
+	for _, line := range fitLines(p.Name, fontRegular, nameSize, inner, 2) {
+		top += nameSize
+		pg.text(x+padding, top, fontRegular, nameSize, line)
+		top += nameSize * 0.2
+	}
+
+	top += priceSize * 1.1
+	price := "Rp " + rupiah.Format(p.Price)
+	pg.text(x+padding, top, fontBold, priceSize, price)
+
+	code := p.SKU
+	if len(p.Barcodes) > 0 {
+		code = p.Barcodes[0]
+	}
+	if code == "" {
+		return
+	}
+	drawBarcode(pg, code, x+padding, top+priceSize*0.4, inner, y+h-padding)
+}
+
+// drawBarcode draws code as an EAN-13 or Code 128 barcode with its text
+// under it, inside the box from (x, top) to (x+w, bottom). Nothing is drawn
+// when the box is too small for bars a scanner could read.
+func drawBarcode(pg *page, code string, x, top, w, bottom float64) {
+	symbology := barcode.TypeCode128
+	if len(code) == 13 && barcode.IsGTIN(code) {
+		symbology = barcode.TypeEAN13
+	}
+	symbol, err := barcode.Encode(symbology, code)
+	if err != nil {
+		return
+	}
+
+	modules := symbol.Modules[0]
+	module := min(w/float64(len(modules)+2*symbol.QuietZone), 1.5)
+	textSize := min(7, (bottom-top)*0.3)
+	barHeight := bottom - top - textSize - 1
+	if module < 0.5 || barHeight < 12 {
+		return
+	}
+
+	left := x + (w-module*float64(len(modules)))/2
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		pg.fillRect(left+float64(start)*module, top, float64(i-start)*module, barHeight)
+	}
+
+	pg.text(x+(w-textWidth(code, fontRegular, textSize))/2, bottom, fontRegular, textSize, code)
+}
+
+// fitLines wraps s into at most maxLines lines no wider than width,
+// shortening the last line with "..." when the text does not fit.
+func fitLines(s, font string, size, width float64, maxLines int) []string {
+	lines := make([]string, 0, maxLines)
+	current := ""
+	words := strings.Fields(s)
+	for i, word := range words {
+		next := strings.TrimSpace(current + " " + word)
+		if current == "" || textWidth(next, font, size) <= width {
+			current = next
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			current = strings.Join(append([]string{current}, words[i:]...), " ")
+			break
+		}
+		lines = append(lines, current)
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, ellipsis(current, font, size, width))
+	}
+	return lines
+}
+
+func ellipsis(s, font string, size, width float64) string {
+	if textWidth(s, font, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", font, size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
@@ -0,0 +1,133 @@
+package label
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Layout
+		wantErr bool
+	}{
+		{s: "3x8", want: Layout{Columns: 3, Rows: 8}},
+		{s: "2X5", want: Layout{Columns: 2, Rows: 5}},
+		{s: "6x15", want: Layout{Columns: 6, Rows: 15}},
+		{s: "7x8", wantErr: true},
+		{s: "3x16", wantErr: true},
+		{s: "0x8", wantErr: true},
+		{s: "3", wantErr: true},
+		{s: "axb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseLayout(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLayout(%q) = %+v, want error", tt.s, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLayout(%q) error = %v", tt.s, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLayout(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+var streamRe = regexp.MustCompile(`(?s)<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+
+// pageContents returns the uncompressed content stream of every page.
+func pageContents(t *testing.T, doc []byte) []string {
+	t.Helper()
+
+	var pages []string
+	for _, m := range streamRe.FindAllSubmatchIndex(doc, -1) {
+		length, _ := strconv.Atoi(string(doc[m[2]:m[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(doc[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, string(content))
+	}
+	return pages
+}
+
+func TestPDF(t *testing.T) {
+	products := make([]models.Product, 0)
+	for i := range 10 {
+		products = append(products, models.Product{
+			Name:     fmt.Sprintf("Produk %d", i+1),
+			Price:    12500,
+			Barcodes: []string{"4006381333931"},
+		})
+	}
+
+	tests := []struct {
+		name      string
+		products  []models.Product
+		layout    Layout
+		copies    int
+		wantPages []int
+	}{
+		{name: "one sheet", products: products, layout: Layout{Columns: 3, Rows: 8}, copies: 1, wantPages: []int{10}},
+		{name: "copies spill onto a second sheet", products: products, layout: Layout{Columns: 3, Rows: 8}, copies: 3, wantPages: []int{24, 6}},
+		{name: "full sheets", products: products[:4], layout: Layout{Columns: 2, Rows: 2}, copies: 2, wantPages: []int{4, 4}},
+		{name: "nothing to print", layout: Layout{Columns: 3, Rows: 8}, copies: 1, wantPages: []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := PDF(tt.products, tt.layout, tt.copies)
+			if err != nil {
+				t.Fatalf("PDF() error = %v", err)
+			}
+			if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+				t.Fatal("PDF() does not look like a PDF document")
+			}
+			if count := fmt.Sprintf("/Count %d ", len(tt.wantPages)); !bytes.Contains(doc, []byte(count)) {
+				t.Errorf("page tree does not contain %q", count)
+			}
+
+			pages := pageContents(t, doc)
+			if len(pages) != len(tt.wantPages) {
+				t.Fatalf("got %d pages, want %d", len(pages), len(tt.wantPages))
+			}
+			for i, content := range pages {
+				// tiap label punya satu garis potong
+				if got := bytes.Count([]byte(content), []byte(" re S ")); got != tt.wantPages[i] {
+					t.Errorf("page %d has %d labels, want %d", i+1, got, tt.wantPages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPDFPrice(t *testing.T) {
+	doc, err := PDF([]models.Product{{Name: "Kopi", Price: 1234567}, {Name: "Gula", Price: 500}}, Layout{Columns: 2, Rows: 1}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := pageContents(t, doc)[0]
+	for _, price := range []string{"(Rp 1.234.567)", "(Rp 500)"} {
+		if !bytes.Contains([]byte(content), []byte(price)) {
+			t.Errorf("page does not show %s", price)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package loyalty holds the rules for earning and spending customer points.
+package loyalty
+
+import "fmt"
+
+type Program struct {
+	// EarnAmount is how many rupiah a customer spends for one point;
+	// 0 turns earning off.
+	EarnAmount int
+	// PointValue is how many rupiah one point is worth when it is spent.
+	PointValue int
+}
+
+func NewProgram(config Program) (*Program, error) {
+	if config.EarnAmount < 0 {
+		return nil, fmt.Errorf("points earn amount must not be negative")
+	}
+	if config.PointValue <= 0 {
+		return nil, fmt.Errorf("point value must be greater than 0")
+	}
+	return &config, nil
+}
+
+// Earned returns the points earned by spending amount.
+func (p *Program) Earned(amount int) int {
+	if p.EarnAmount == 0 || amount <= 0 {
+		return 0
+	}
+	return amount / p.EarnAmount
+}
+
+// Redeemed returns how many points pay for amount rupiah. amount must be a
+// whole number of points.
+func (p *Program) Redeemed(amount int) (int, error) {
+	if amount%p.PointValue != 0 {
+		return 0, fmt.Errorf("points payments must be a multiple of %d", p.PointValue)
+	}
+	return amount / p.PointValue, nil
+}
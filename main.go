@@ -13,10 +13,16 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"kasir-api/barcode"
 	"kasir-api/database"
 	_ "kasir-api/docs"
 	"kasir-api/handlers"
+	"kasir-api/invoice"
+	"kasir-api/loyalty"
+	"kasir-api/pricing"
+	"kasir-api/receipt"
 	"kasir-api/repositories"
 	"kasir-api/services"
 
@@ -25,13 +31,48 @@ import (
 )
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port                   string        `mapstructure:"PORT"`
+	DBConn                 string        `mapstructure:"DB_CONN"`
+	IdempotencyKeyTTL      time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	MaxDiscountPercent     int           `mapstructure:"MAX_DISCOUNT_PERCENT"`
+	TaxRate                float64       `mapstructure:"TAX_RATE"`
+	PricesIncludeTax       bool          `mapstructure:"PRICES_INCLUDE_TAX"`
+	StoreName              string        `mapstructure:"STORE_NAME"`
+	StoreAddress           string        `mapstructure:"STORE_ADDRESS"`
+	StorePhone             string        `mapstructure:"STORE_PHONE"`
+	ReceiptFooter          string        `mapstructure:"RECEIPT_FOOTER"`
+	ReceiptWidth           int           `mapstructure:"RECEIPT_WIDTH"`
+	InvoiceFormat          string        `mapstructure:"INVOICE_FORMAT"`
+	OutletCode             string        `mapstructure:"OUTLET_CODE"`
+	MaxItemQuantity        int           `mapstructure:"MAX_ITEM_QUANTITY"`
+	MaxTransactionQuantity int           `mapstructure:"MAX_TRANSACTION_QUANTITY"`
+	CashRounding           string        `mapstructure:"CASH_ROUNDING"`
+	CashRoundingIncrement  int           `mapstructure:"CASH_ROUNDING_INCREMENT"`
+	RequireShift           bool          `mapstructure:"REQUIRE_SHIFT"`
+	PointsEarnAmount       int           `mapstructure:"POINTS_EARN_AMOUNT"`
+	PointValue             int           `mapstructure:"POINT_VALUE"`
+	InternalBarcodePrefix  string        `mapstructure:"INTERNAL_BARCODE_PREFIX"`
+	LowStockThreshold      int           `mapstructure:"LOW_STOCK_THRESHOLD"`
 }
 
 func main() {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("MAX_DISCOUNT_PERCENT", 100)
+	viper.SetDefault("TAX_RATE", 0)
+	viper.SetDefault("PRICES_INCLUDE_TAX", true)
+	viper.SetDefault("RECEIPT_FOOTER", "Terima kasih")
+	viper.SetDefault("RECEIPT_WIDTH", 32)
+	viper.SetDefault("INVOICE_FORMAT", invoice.DefaultFormat)
+	viper.SetDefault("MAX_ITEM_QUANTITY", 1000)
+	viper.SetDefault("MAX_TRANSACTION_QUANTITY", 10000)
+	viper.SetDefault("CASH_ROUNDING", pricing.RoundingNone)
+	viper.SetDefault("CASH_ROUNDING_INCREMENT", 100)
+	viper.SetDefault("POINTS_EARN_AMOUNT", 10000)
+	viper.SetDefault("POINT_VALUE", 1)
+	viper.SetDefault("INTERNAL_BARCODE_PREFIX", "200")
+	viper.SetDefault("LOW_STOCK_THRESHOLD", 5)
 
 	if _, err := os.Stat(".env"); err == nil {
 		viper.SetConfigFile(".env")
@@ -39,8 +80,28 @@ func main() {
 	}
 
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:                   viper.GetString("PORT"),
+		DBConn:                 viper.GetString("DB_CONN"),
+		IdempotencyKeyTTL:      viper.GetDuration("IDEMPOTENCY_KEY_TTL"),
+		MaxDiscountPercent:     viper.GetInt("MAX_DISCOUNT_PERCENT"),
+		TaxRate:                viper.GetFloat64("TAX_RATE"),
+		PricesIncludeTax:       viper.GetBool("PRICES_INCLUDE_TAX"),
+		StoreName:              viper.GetString("STORE_NAME"),
+		StoreAddress:           viper.GetString("STORE_ADDRESS"),
+		StorePhone:             viper.GetString("STORE_PHONE"),
+		ReceiptFooter:          viper.GetString("RECEIPT_FOOTER"),
+		ReceiptWidth:           viper.GetInt("RECEIPT_WIDTH"),
+		InvoiceFormat:          viper.GetString("INVOICE_FORMAT"),
+		OutletCode:             viper.GetString("OUTLET_CODE"),
+		MaxItemQuantity:        viper.GetInt("MAX_ITEM_QUANTITY"),
+		MaxTransactionQuantity: viper.GetInt("MAX_TRANSACTION_QUANTITY"),
+		CashRounding:           viper.GetString("CASH_ROUNDING"),
+		CashRoundingIncrement:  viper.GetInt("CASH_ROUNDING_INCREMENT"),
+		RequireShift:           viper.GetBool("REQUIRE_SHIFT"),
+		PointsEarnAmount:       viper.GetInt("POINTS_EARN_AMOUNT"),
+		PointValue:             viper.GetInt("POINT_VALUE"),
+		InternalBarcodePrefix:  viper.GetString("INTERNAL_BARCODE_PREFIX"),
+		LowStockThreshold:      viper.GetInt("LOW_STOCK_THRESHOLD"),
 	}
 
 	db, err := database.InitDB(config.DBConn)
@@ -49,18 +110,71 @@ func main() {
 	}
 	defer db.Close()
 
+	if err := database.Migrate(db); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
 	categoryRepo := repositories.NewCategoryRepository(db)
 	categoryService := services.NewCategoryService(categoryRepo)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
-	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo, categoryRepo)
+	internalBarcodes, err := barcode.NewInternalRange(config.InternalBarcodePrefix)
+	if err != nil {
+		log.Fatal("Invalid INTERNAL_BARCODE_PREFIX:", err)
+	}
+	productRepo := repositories.NewProductRepository(db, internalBarcodes)
+	productService := services.NewProductService(productRepo, categoryRepo, config.LowStockThreshold)
 	productHandler := handlers.NewProductHandler(productService)
 
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
+	calculator := pricing.NewCalculator(pricing.Calculator{
+		MaxDiscountPercent:    config.MaxDiscountPercent,
+		TaxRate:               config.TaxRate,
+		PricesIncludeTax:      config.PricesIncludeTax,
+		CashRounding:          config.CashRounding,
+		CashRoundingIncrement: config.CashRoundingIncrement,
+	})
+	if err := calculator.ValidateRounding(); err != nil {
+		log.Fatal("Invalid CASH_ROUNDING:", err)
+	}
+	numberer, err := invoice.NewNumberer(config.InvoiceFormat, config.OutletCode)
+	if err != nil {
+		log.Fatal("Invalid INVOICE_FORMAT:", err)
+	}
+	program, err := loyalty.NewProgram(loyalty.Program{
+		EarnAmount: config.PointsEarnAmount,
+		PointValue: config.PointValue,
+	})
+	if err != nil {
+		log.Fatal("Invalid loyalty settings:", err)
+	}
+	transactionRepo := repositories.NewTransactionRepository(db, calculator, numberer, program, config.RequireShift)
+	refundRepo := repositories.NewRefundRepository(db, config.RequireShift)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	printer := receipt.NewPrinter(receipt.Store{
+		Name:    config.StoreName,
+		Address: config.StoreAddress,
+		Phone:   config.StorePhone,
+		Footer:  config.ReceiptFooter,
+	}, config.ReceiptWidth)
+	transactionService := services.NewTransactionService(transactionRepo, refundRepo, idempotencyRepo, config.IdempotencyKeyTTL, printer, services.CheckoutLimits{
+		MaxItemQuantity:        config.MaxItemQuantity,
+		MaxTransactionQuantity: config.MaxTransactionQuantity,
+	})
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 
+	orderRepo := repositories.NewOrderRepository(db, transactionRepo)
+	orderService := services.NewOrderService(orderRepo, transactionService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+
+	customerRepo := repositories.NewCustomerRepository(db)
+	receivableRepo := repositories.NewReceivableRepository(db, config.RequireShift)
+	customerService := services.NewCustomerService(customerRepo, receivableRepo)
+	customerHandler := handlers.NewCustomerHandler(customerService)
+
+	shiftRepo := repositories.NewShiftRepository(db)
+	shiftService := services.NewShiftService(shiftRepo)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+
 	reportRepo := repositories.NewReportRepository(db)
 	reportService := services.NewReportService(reportRepo)
 	reportHandler := handlers.NewReportHandler(reportService)
@@ -75,6 +189,24 @@ func main() {
 
 	// checkout API
 	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout)
+	http.HandleFunc("/api/checkout/quote", transactionHandler.HandleQuote)
+
+	// transactions API
+	http.HandleFunc("/api/transactions", transactionHandler.HandleTransactions)
+	http.HandleFunc("/api/transactions/", transactionHandler.HandleTransactionByID)
+	http.HandleFunc("/api/transactions/sync", transactionHandler.HandleSync)
+
+	// orders API
+	http.HandleFunc("/api/orders", orderHandler.HandleOrders)
+	http.HandleFunc("/api/orders/", orderHandler.HandleOrderByID)
+
+	// customers API
+	http.HandleFunc("/api/customers", customerHandler.HandleCustomers)
+	http.HandleFunc("/api/customers/", customerHandler.HandleCustomerByID)
+
+	// shifts API
+	http.HandleFunc("/api/shifts", shiftHandler.HandleShifts)
+	http.HandleFunc("/api/shifts/", shiftHandler.HandleShiftByID)
 
 	// report API
 	http.HandleFunc("/api/report", reportHandler.HandleReport)
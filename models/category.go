@@ -1,6 +1,7 @@
 package models
 
 type Category struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	TaxRate *float64 `json:"tax_rate,omitempty"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	PointsEarn    = "earn"
+	PointsRedeem  = "redeem"
+	PointsReverse = "reverse"
+	PointsRestore = "restore"
+)
+
+// Customer is a registered customer. CreditLimit is how much they may owe
+// at once through "on_account" payments (0 means no credit); Outstanding is
+// how much they owe now.
+type Customer struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Phone       string    `json:"phone"`
+	Email       string    `json:"email,omitempty"`
+	Points      int       `json:"points"`
+	CreditLimit int       `json:"credit_limit"`
+	Outstanding int       `json:"outstanding"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PointsEntry is one change to a customer's point balance. Points is
+// positive for points added and negative for points taken off.
+type PointsEntry struct {
+	ID            int       `json:"id"`
+	CustomerID    int       `json:"customer_id"`
+	Type          string    `json:"type"`
+	Points        int       `json:"points"`
+	BalanceAfter  int       `json:"balance_after"`
+	TransactionID *int      `json:"transaction_id,omitempty"`
+	RefundID      *int      `json:"refund_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PointsHistory struct {
+	CustomerID int           `json:"customer_id"`
+	Balance    int           `json:"balance"`
+	Entries    []PointsEntry `json:"entries"`
+}
@@ -0,0 +1,13 @@
+package models
+
+const (
+	DiscountTypePercent = "percent"
+	DiscountTypeFixed   = "fixed"
+)
+
+// Discount is either a whole-number percentage (Value 10 means 10%) or a
+// fixed amount in rupiah.
+type Discount struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "fmt"
+
+// FieldError points at a single invalid field in a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a request is well-formed but its
+// content cannot be accepted.
+type ValidationError struct {
+	Message string       `json:"error"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// NotFoundError is returned when the resource a request refers to does not exist.
+type NotFoundError struct {
+	Resource string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s is not found", e.Resource)
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// IdempotencyKey ties a client-supplied Idempotency-Key header to the
+// checkout it produced, so retries can be answered without a second sale.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	ExpiresAt   time.Time
+	Response    *Transaction
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+const (
+	OrderStatusOpen      = "open"
+	OrderStatusSettled   = "settled"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Order is an open bill that is built up over time, for example a table in
+// a café, and settled into a Transaction once the customer pays. Stock is
+// only taken when the order is settled.
+type Order struct {
+	ID            int         `json:"id"`
+	Label         string      `json:"label"`
+	Cashier       string      `json:"cashier,omitempty"`
+	Status        string      `json:"status"`
+	TransactionID *int        `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Items         []OrderItem `json:"items"`
+}
+
+// OrderItem is a line on an open order. Price is the product's current
+// price; the price charged is fixed only when the order is settled.
+type OrderItem struct {
+	ID          int       `json:"id"`
+	OrderID     int       `json:"order_id"`
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Price       int       `json:"price"`
+	Quantity    int       `json:"quantity"`
+	Discount    *Discount `json:"discount,omitempty"`
+	Note        string    `json:"note,omitempty"`
+}
+
+type OrderRequest struct {
+	Label   string             `json:"label"`
+	Cashier string             `json:"cashier,omitempty"`
+	Items   []OrderItemRequest `json:"items"`
+}
+
+type OrderItemRequest struct {
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Discount  *Discount `json:"discount,omitempty"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// SettleOrderRequest pays for an order. The items come from the order
+// itself; everything else is the same as in a CheckoutRequest.
+type SettleOrderRequest struct {
+	OutletCode string            `json:"outlet_code,omitempty"`
+	ShiftID    int               `json:"shift_id,omitempty"`
+	CustomerID int               `json:"customer_id,omitempty"`
+	Cashier    string            `json:"cashier,omitempty"`
+	Discount   *Discount         `json:"discount,omitempty"`
+	Payments   []CheckoutPayment `json:"payments"`
+}
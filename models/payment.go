@@ -0,0 +1,44 @@
+package models
+
+const (
+	PaymentMethodCash      = "cash"
+	PaymentMethodQRIS      = "qris"
+	PaymentMethodDebitCard = "debit_card"
+	PaymentMethodEWallet   = "e_wallet"
+	PaymentMethodPoints    = "points"
+	PaymentMethodOnAccount = "on_account"
+)
+
+// PaymentMethods lists every method accepted at checkout.
+var PaymentMethods = []string{
+	PaymentMethodCash,
+	PaymentMethodQRIS,
+	PaymentMethodDebitCard,
+	PaymentMethodEWallet,
+	PaymentMethodPoints,
+	PaymentMethodOnAccount,
+}
+
+// RepaymentMethods lists the methods a customer can settle their debt with.
+var RepaymentMethods = []string{
+	PaymentMethodCash,
+	PaymentMethodQRIS,
+	PaymentMethodDebitCard,
+	PaymentMethodEWallet,
+}
+
+type Payment struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+// CheckoutPayment is one tender in a checkout request. A sale may be split
+// across several of them.
+type CheckoutPayment struct {
+	Method    string `json:"method"`
+	Amount    int    `json:"amount"`
+	Reference string `json:"reference,omitempty"`
+}
@@ -1,10 +1,151 @@
 package models
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Product is an item for sale. SKU is the store's own code for it and
+// Barcodes the codes printed on its packaging; a scanner can find the
+// product by any of them.
+//
+// An update only changes the fields it sends; every field left out keeps
+// its current value. To clear one, send it empty: "sku": "" removes the
+// SKU, "barcodes": [] the barcodes and "tax_rate": null the product's own
+// tax rate.
+//
+// A product can be sold in variants, e.g. a t-shirt in sizes and colours.
+// The parent lists the VariantOptions; each variant is a product of its own
+// with its own SKU, barcodes, price and stock, a ParentID and the Options
+// it was made with. A parent that has variants cannot be sold itself.
 type Product struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Price        int    `json:"price"`
-	Stock        int    `json:"stock"`
-	CategoryID   int    `json:"category_id"`
-	CategoryName string `json:"category_name,omitempty"`
+	ID             int             `json:"id"`
+	Name           string          `json:"name"`
+	SKU            string          `json:"sku,omitempty"`
+	Barcodes       []string        `json:"barcodes"`
+	Price          int             `json:"price"`
+	Stock          int             `json:"stock"`
+	AllowBackorder bool            `json:"allow_backorder"`
+	TaxRate        *float64        `json:"tax_rate,omitempty"`
+	CategoryID     int             `json:"category_id"`
+	CategoryName   string          `json:"category_name,omitempty"`
+	ParentID       *int            `json:"parent_id,omitempty"`
+	Options        VariantValues   `json:"options,omitempty"`
+	VariantOptions []VariantOption `json:"variant_options,omitempty"`
+	HasVariants    bool            `json:"has_variants,omitempty"`
+	Variants       []Product       `json:"variants,omitempty"`
+}
+
+// VariantOption is one way a product varies, e.g. size with the values S,
+// M and L.
+type VariantOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// VariantValues picks one value for each variant option of the parent,
+// keyed by option name.
+type VariantValues map[string]string
+
+// Check reports whether values picks exactly one allowed value for every
+// option in options.
+func (values VariantValues) Check(options []VariantOption) error {
+	if len(options) == 0 {
+		return fmt.Errorf("product has no variant options")
+	}
+	if len(values) != len(options) {
+		return fmt.Errorf("options must give a value for each of %s", optionNames(options))
+	}
+	for _, o := range options {
+		value, ok := values[o.Name]
+		if !ok {
+			return fmt.Errorf("options must give a value for each of %s", optionNames(options))
+		}
+		if !slices.Contains(o.Values, value) {
+			return fmt.Errorf("%s must be one of %s", o.Name, strings.Join(o.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// Label joins the values in the order of options, e.g. "M / Red".
+func (values VariantValues) Label(options []VariantOption) string {
+	parts := make([]string, 0, len(options))
+	for _, o := range options {
+		parts = append(parts, values[o.Name])
+	}
+	return strings.Join(parts, " / ")
+}
+
+func optionNames(options []VariantOption) string {
+	names := make([]string, len(options))
+	for i, o := range options {
+		names[i] = o.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// LabelFilter picks the products to print shelf labels for. Exactly one of
+// the fields is set.
+type LabelFilter struct {
+	IDs        []int
+	CategoryID int
+	// ChangedSince is a YYYY-MM-DD date; products whose price changed on or
+	// after it are picked.
+	ChangedSince string
+}
+
+// Product list sort keys and stock filters.
+const (
+	ProductSortName  = "name"
+	ProductSortPrice = "price"
+	ProductSortStock = "stock"
+	ProductSortID    = "id"
+
+	StockIn  = "in"
+	StockOut = "out"
+	StockLow = "low"
+)
+
+// ProductFilter narrows down and pages the product listing. Zero values
+// mean "no filter". Cursor is the X-Next-Cursor of the previous page and is
+// only valid with the same sort and order.
+type ProductFilter struct {
+	Name       string
+	CategoryID int
+	MinPrice   *int
+	MaxPrice   *int
+	// Stock is "in", "out" or "low"; low means in stock but at or under
+	// LowStockThreshold.
+	Stock             string
+	LowStockThreshold int
+	Sort              string
+	Desc              bool
+	Limit             int
+	Cursor            string
+}
+
+// ProductPage is one page of the product listing.
+type ProductPage struct {
+	Products   []Product
+	Total      int
+	NextCursor string
+}
+
+// ProductMatch is a product found by search. Score ranks the matches:
+// an exact SKU or barcode counts most, then a name starting with the
+// query, then how close the name and category are to it.
+type ProductMatch struct {
+	Product
+	Score float64 `json:"score"`
+}
+
+// ProductSuggestion is a trimmed down product for search-as-you-type.
+type ProductSuggestion struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	SKU   string `json:"sku,omitempty"`
+	Price int    `json:"price"`
+	Stock int    `json:"stock"`
 }
@@ -0,0 +1,22 @@
+package models
+
+const (
+	QuoteWarningInsufficientStock = "insufficient_stock"
+	QuoteWarningBackorder         = "backorder"
+	QuoteWarningPayments          = "payments"
+)
+
+// CheckoutQuote is what a checkout would produce, without anything being
+// saved. Warnings list the problems that would make the checkout fail or
+// that the cashier should know about before taking payment.
+type CheckoutQuote struct {
+	Transaction *Transaction   `json:"transaction"`
+	Warnings    []QuoteWarning `json:"warnings"`
+}
+
+type QuoteWarning struct {
+	Code      string `json:"code"`
+	Field     string `json:"field"`
+	ProductID int    `json:"product_id,omitempty"`
+	Message   string `json:"message"`
+}
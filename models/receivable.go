@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Receivable is what a customer owes for a sale paid "on account" (kasbon).
+// Outstanding = Amount - PaidAmount - CreditedAmount, where CreditedAmount
+// is taken off by refunds of the sale.
+type Receivable struct {
+	ID             int       `json:"id"`
+	CustomerID     int       `json:"customer_id"`
+	TransactionID  int       `json:"transaction_id"`
+	InvoiceNumber  string    `json:"invoice_number"`
+	Amount         int       `json:"amount"`
+	PaidAmount     int       `json:"paid_amount"`
+	CreditedAmount int       `json:"credited_amount"`
+	Outstanding    int       `json:"outstanding"`
+	AgeDays        int       `json:"age_days"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CustomerReceivables struct {
+	CustomerID  int          `json:"customer_id"`
+	CreditLimit int          `json:"credit_limit"`
+	Outstanding int          `json:"outstanding"`
+	Receivables []Receivable `json:"receivables"`
+}
+
+// Repayment is money a customer pays towards their debt. It is applied to
+// their oldest receivables first.
+type Repayment struct {
+	ID          int                   `json:"id"`
+	CustomerID  int                   `json:"customer_id"`
+	Amount      int                   `json:"amount"`
+	Method      string                `json:"method"`
+	Reference   string                `json:"reference,omitempty"`
+	ShiftID     *int                  `json:"shift_id,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Allocations []RepaymentAllocation `json:"allocations"`
+}
+
+type RepaymentAllocation struct {
+	ReceivableID  int `json:"receivable_id"`
+	TransactionID int `json:"transaction_id"`
+	Amount        int `json:"amount"`
+}
+
+type RepaymentRequest struct {
+	Amount    int    `json:"amount"`
+	Method    string `json:"method"`
+	Reference string `json:"reference,omitempty"`
+	ShiftID   int    `json:"shift_id,omitempty"`
+	Cashier   string `json:"cashier,omitempty"`
+}
+
+// AgingBuckets splits outstanding debt by how old it is.
+type AgingBuckets struct {
+	Days0To30  int `json:"days_0_30"`
+	Days31To60 int `json:"days_31_60"`
+	Over60     int `json:"days_over_60"`
+	Total      int `json:"total"`
+}
+
+type CustomerAging struct {
+	CustomerID  int    `json:"customer_id"`
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	CreditLimit int    `json:"credit_limit"`
+	AgingBuckets
+}
+
+type AgingReport struct {
+	Totals    AgingBuckets    `json:"totals"`
+	Customers []CustomerAging `json:"customers"`
+}
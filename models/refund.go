@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusPartiallyRefunded = "partially_refunded"
+	TransactionStatusRefunded          = "refunded"
+	TransactionStatusVoided            = "voided"
+
+	RefundTypeVoid   = "void"
+	RefundTypeRefund = "refund"
+)
+
+// Refund is money (and stock) going back for a transaction. CreditAmount is
+// the part taken off the customer's unpaid debt for the sale, CashAmount the
+// part paid out of the drawer. RoundingAmount is this refund's share of the
+// sale's cash rounding; it is paid back with the cash, so the refund pays
+// out TotalAmount + RoundingAmount in all. PointsReversed is how many earned points
+// were taken back from the customer; PointsRestored is how many spent points
+// were given back.
+type Refund struct {
+	ID             int            `json:"id"`
+	TransactionID  int            `json:"transaction_id"`
+	Type           string         `json:"type"`
+	TotalAmount    int            `json:"total_amount"`
+	TaxAmount      int            `json:"tax_amount"`
+	CreditAmount   int            `json:"credit_amount"`
+	CashAmount     int            `json:"cash_amount"`
+	RoundingAmount int            `json:"rounding_amount"`
+	ShiftID        *int           `json:"shift_id,omitempty"`
+	PointsReversed int            `json:"points_reversed"`
+	PointsRestored int            `json:"points_restored"`
+	Reason         string         `json:"reason,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Details        []RefundDetail `json:"details"`
+}
+
+type RefundDetail struct {
+	ID                  int    `json:"id"`
+	RefundID            int    `json:"refund_id"`
+	TransactionDetailID int    `json:"transaction_detail_id"`
+	ProductID           int    `json:"product_id"`
+	ProductName         string `json:"product_name"`
+	Quantity            int    `json:"quantity"`
+	Amount              int    `json:"amount"`
+	TaxAmount           int    `json:"tax_amount"`
+}
+
+type VoidRequest struct {
+	Reason  string `json:"reason"`
+	ShiftID int    `json:"shift_id,omitempty"`
+	Cashier string `json:"cashier,omitempty"`
+}
+
+type RefundRequest struct {
+	Reason  string       `json:"reason"`
+	ShiftID int          `json:"shift_id,omitempty"`
+	Cashier string       `json:"cashier,omitempty"`
+	Items   []RefundItem `json:"items"`
+}
+
+type RefundItem struct {
+	TransactionDetailID int `json:"transaction_detail_id"`
+	Quantity            int `json:"quantity"`
+}
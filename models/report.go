@@ -6,7 +6,36 @@ type BestSellingProduct struct {
 }
 
 type TodayReport struct {
+	GrossSales        int                `json:"gross_sales"`
+	TotalDiscounts    int                `json:"total_discounts"`
+	NetSales          int                `json:"net_sales"`
+	TotalTax          int                `json:"total_tax"`
+	TotalRefunds      int                `json:"total_refunds"`
+	TotalRounding     int                `json:"total_rounding"`
 	TotalRevenue      int                `json:"total_revenue"`
 	TotalTransactions int                `json:"total_transactions"`
 	BestProduct       BestSellingProduct `json:"best_product"`
 }
+
+// Product sales report groupings.
+const (
+	GroupByProduct = "product"
+	GroupByParent  = "parent"
+)
+
+// ProductSales is what one product sold in a period, net of refunds.
+// Grouped by parent, ProductID is the parent and the figures add up all of
+// its variants.
+type ProductSales struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	QtySold   int    `json:"qty_sold"`
+	Revenue   int    `json:"revenue"`
+}
+
+type ProductSalesReport struct {
+	StartDate string         `json:"start_date"`
+	EndDate   string         `json:"end_date"`
+	GroupBy   string         `json:"group_by"`
+	Products  []ProductSales `json:"products"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+
+	CashMovementIn  = "in"
+	CashMovementOut = "out"
+)
+
+// Shift is a cashier's session at the drawer. ExpectedCash is what should
+// be in the drawer: OpeningFloat + CashSales - CashRefunds + CashRepayments
+// + CashIn - CashOut. Once the shift is closed, Variance = CountedCash - ExpectedCash;
+// a negative variance means the drawer is short. Cash from an offline sale
+// synced after the shift closed was already in the counted drawer, so it is
+// added to ExpectedCash too and the variance is updated with it.
+type Shift struct {
+	ID                int            `json:"id"`
+	Cashier           string         `json:"cashier"`
+	Status            string         `json:"status"`
+	OpeningFloat      int            `json:"opening_float"`
+	CashSales         int            `json:"cash_sales"`
+	CashRefunds       int            `json:"cash_refunds"`
+	CashRepayments    int            `json:"cash_repayments"`
+	CashIn            int            `json:"cash_in"`
+	CashOut           int            `json:"cash_out"`
+	ExpectedCash      int            `json:"expected_cash"`
+	CountedCash       *int           `json:"counted_cash"`
+	Variance          *int           `json:"variance"`
+	TotalTransactions int            `json:"total_transactions"`
+	Note              string         `json:"note,omitempty"`
+	OpenedAt          time.Time      `json:"opened_at"`
+	ClosedAt          *time.Time     `json:"closed_at"`
+	Movements         []CashMovement `json:"movements,omitempty"`
+}
+
+// CashMovement is cash put into or taken out of the drawer outside of a
+// sale, such as petty cash or a bank drop.
+type CashMovement struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	Type      string    `json:"type"`
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OpenShiftRequest struct {
+	Cashier      string `json:"cashier"`
+	OpeningFloat int    `json:"opening_float"`
+}
+
+type CashMovementRequest struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash *int   `json:"counted_cash"`
+	Note        string `json:"note,omitempty"`
+}
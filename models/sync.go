@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	SyncStatusAccepted  = "accepted"
+	SyncStatusDuplicate = "duplicate"
+	SyncStatusRejected  = "rejected"
+)
+
+// SyncTransaction is a sale made on a till while it was offline. ClientID
+// is the UUID the till gave the sale and makes uploading it again harmless;
+// CreatedAt is when the sale actually happened. The sale stays on the shift
+// it was rung up on, even if that shift has been closed since.
+type SyncTransaction struct {
+	ClientID  string    `json:"client_id"`
+	CreatedAt time.Time `json:"created_at"`
+	CheckoutRequest
+}
+
+type SyncRequest struct {
+	Transactions []SyncTransaction `json:"transactions"`
+}
+
+// SyncResult is the outcome for one uploaded sale. A rejected sale carries
+// the reason, and the fields or stock shortages behind it.
+type SyncResult struct {
+	ClientID      string          `json:"client_id"`
+	Status        string          `json:"status"`
+	TransactionID int             `json:"transaction_id,omitempty"`
+	InvoiceNumber string          `json:"invoice_number,omitempty"`
+	Reason        string          `json:"reason,omitempty"`
+	Fields        []FieldError    `json:"fields,omitempty"`
+	Shortages     []StockShortage `json:"shortages,omitempty"`
+}
+
+type SyncResponse struct {
+	Accepted   int          `json:"accepted"`
+	Duplicates int          `json:"duplicates"`
+	Rejected   int          `json:"rejected"`
+	Results    []SyncResult `json:"results"`
+}
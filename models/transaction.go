@@ -1,25 +1,114 @@
 package models
 
+import "time"
+
+// Transaction amounts: GrossAmount - DiscountAmount is what the items cost
+// at their (possibly tax-inclusive) prices. Subtotal is that amount before
+// tax, and TotalAmount = Subtotal + TaxAmount is what the sale is worth.
+// RoundingAmount is added when the cash part is rounded, so the customer
+// pays TotalAmount + RoundingAmount.
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int                 `json:"id"`
+	InvoiceNumber  string              `json:"invoice_number"`
+	OutletCode     string              `json:"outlet_code,omitempty"`
+	GrossAmount    int                 `json:"gross_amount"`
+	DiscountAmount int                 `json:"discount_amount"`
+	Subtotal       int                 `json:"subtotal"`
+	TaxAmount      int                 `json:"tax_amount"`
+	TotalAmount    int                 `json:"total_amount"`
+	RoundingAmount int                 `json:"rounding_amount"`
+	PaidAmount     int                 `json:"paid_amount"`
+	ChangeAmount   int                 `json:"change_amount"`
+	Status         string              `json:"status"`
+	ShiftID        *int                `json:"shift_id,omitempty"`
+	CustomerID     *int                `json:"customer_id,omitempty"`
+	PointsEarned   int                 `json:"points_earned"`
+	PointsRedeemed int                 `json:"points_redeemed"`
+	Cashier        string              `json:"cashier,omitempty"`
+	ClientID       string              `json:"client_id,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	SyncedAt       *time.Time          `json:"synced_at,omitempty"`
+	Details        []TransactionDetail `json:"details"`
+	Payments       []Payment           `json:"payments"`
+	Refunds        []Refund            `json:"refunds,omitempty"`
 }
 
+// TransactionDetail amounts: GrossAmount = UnitPrice * Quantity, Subtotal =
+// GrossAmount - DiscountAmount, and TotalAmount is what the customer pays for
+// the line including tax. ProductName and UnitPrice are copied from the
+// product at checkout, so later changes to the product do not alter the sale.
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID               int     `json:"id"`
+	TransactionID    int     `json:"transaction_id"`
+	ProductID        int     `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	SKU              string  `json:"sku,omitempty"`
+	ParentID         *int    `json:"parent_id,omitempty"`
+	UnitPrice        int     `json:"unit_price"`
+	Quantity         int     `json:"quantity"`
+	RefundedQuantity int     `json:"refunded_quantity"`
+	GrossAmount      int     `json:"gross_amount"`
+	DiscountAmount   int     `json:"discount_amount"`
+	Subtotal         int     `json:"subtotal"`
+	TaxRate          float64 `json:"tax_rate"`
+	TaxAmount        int     `json:"tax_amount"`
+	TotalAmount      int     `json:"total_amount"`
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	OutletCode string            `json:"outlet_code,omitempty"`
+	ShiftID    int               `json:"shift_id,omitempty"`
+	CustomerID int               `json:"customer_id,omitempty"`
+	Cashier    string            `json:"cashier,omitempty"`
+	Items      []CheckoutItem    `json:"items"`
+	Discount   *Discount         `json:"discount,omitempty"`
+	Payments   []CheckoutPayment `json:"payments"`
 }
 
+// CheckoutItem is one line of a checkout. The product is given either by
+// ProductID or by a scanned Barcode.
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int       `json:"product_id,omitempty"`
+	Barcode   string    `json:"barcode,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Discount  *Discount `json:"discount,omitempty"`
+}
+
+// TransactionFilter narrows down the transaction history listing.
+// Zero values mean "no filter".
+type TransactionFilter struct {
+	Page          int
+	Limit         int
+	StartDate     string
+	EndDate       string
+	MinAmount     *int
+	MaxAmount     *int
+	ProductID     int
+	PaymentMethod string
+	InvoiceNumber string
+}
+
+type TransactionList struct {
+	Data  []Transaction `json:"data"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int           `json:"total"`
+}
+
+// StockShortage describes a checkout line that asks for more than is in stock.
+type StockShortage struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Requested   int    `json:"requested"`
+	Available   int    `json:"available"`
+}
+
+// InsufficientStockError is returned when a checkout would drive stock negative.
+type InsufficientStockError struct {
+	Message string          `json:"error"`
+	Items   []StockShortage `json:"items"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	return e.Message
 }
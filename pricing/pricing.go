@@ -0,0 +1,168 @@
+// Package pricing turns a checkout request and the products it refers to
+// into priced transaction lines. It does no I/O, so the same rules apply
+// whether a basket is being sold or only quoted.
+package pricing
+
+import (
+	"fmt"
+	"kasir-api/models"
+)
+
+type Calculator struct {
+	// MaxDiscountPercent caps the discount a cashier may give, as a
+	// percentage of the gross amount, both per line and for the whole order.
+	MaxDiscountPercent int
+	// TaxRate is the store-wide tax rate in percent, used for products
+	// whose product and category have no rate of their own.
+	TaxRate float64
+	// PricesIncludeTax tells whether product prices already contain tax.
+	PricesIncludeTax bool
+	// CashRounding is how cash amounts are rounded: none, nearest, up or
+	// down, to a multiple of CashRoundingIncrement rupiah.
+	CashRounding          string
+	CashRoundingIncrement int
+}
+
+func NewCalculator(config Calculator) *Calculator {
+	return &config
+}
+
+// Price prices every item of req. products must contain every product
+// referenced by req.Items.
+func (c *Calculator) Price(req models.CheckoutRequest, products map[int]*models.Product) (*models.Transaction, error) {
+	res := &models.Transaction{
+		Details: make([]models.TransactionDetail, 0, len(req.Items)),
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	for i, item := range req.Items {
+		p := products[item.ProductID]
+
+		gross := item.Quantity * p.Price
+		discount, err := c.discountAmount(item.Discount, gross)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].discount", i),
+				Message: err.Error(),
+			})
+		}
+
+		res.Details = append(res.Details, models.TransactionDetail{
+			ProductID:      p.ID,
+			ProductName:    p.Name,
+			SKU:            p.SKU,
+			ParentID:       p.ParentID,
+			UnitPrice:      p.Price,
+			Quantity:       item.Quantity,
+			GrossAmount:    gross,
+			DiscountAmount: discount,
+		})
+		res.GrossAmount += gross
+	}
+
+	// diskon order dibagi ke tiap baris sesuai porsinya, supaya refund
+	// per baris tetap mengembalikan harga yang benar-benar dibayar
+	lineNet := 0
+	for _, d := range res.Details {
+		lineNet += d.GrossAmount - d.DiscountAmount
+	}
+	orderDiscount, err := c.discountAmount(req.Discount, lineNet)
+	if err != nil {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "discount", Message: err.Error()})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid discount", Fields: fieldErrors}
+	}
+	allocate(res.Details, orderDiscount)
+
+	for i := range res.Details {
+		d := &res.Details[i]
+		d.Subtotal = d.GrossAmount - d.DiscountAmount
+		res.DiscountAmount += d.DiscountAmount
+	}
+
+	if limit := res.GrossAmount * c.MaxDiscountPercent / 100; res.DiscountAmount > limit {
+		return nil, &models.ValidationError{
+			Message: "invalid discount",
+			Fields: []models.FieldError{{
+				Field:   "discount",
+				Message: fmt.Sprintf("total discount of %d exceeds the maximum of %d%% (%d)", res.DiscountAmount, c.MaxDiscountPercent, limit),
+			}},
+		}
+	}
+
+	c.applyTax(res, products)
+
+	return res, nil
+}
+
+// discountAmount works out how much d takes off amount.
+func (c *Calculator) discountAmount(d *models.Discount, amount int) (int, error) {
+	if d == nil {
+		return 0, nil
+	}
+
+	var discount int
+	switch d.Type {
+	case models.DiscountTypePercent:
+		if d.Value < 0 || d.Value > 100 {
+			return 0, fmt.Errorf("percent discount must be between 0 and 100")
+		}
+		discount = roundDiv(amount*d.Value, 100)
+	case models.DiscountTypeFixed:
+		if d.Value < 0 {
+			return 0, fmt.Errorf("fixed discount must not be negative")
+		}
+		if d.Value > amount {
+			return 0, fmt.Errorf("fixed discount of %d exceeds the amount of %d", d.Value, amount)
+		}
+		discount = d.Value
+	default:
+		return 0, fmt.Errorf("discount type must be %q or %q", models.DiscountTypePercent, models.DiscountTypeFixed)
+	}
+
+	if limit := amount * c.MaxDiscountPercent / 100; discount > limit {
+		return 0, fmt.Errorf("discount of %d exceeds the maximum of %d%% (%d)", discount, c.MaxDiscountPercent, limit)
+	}
+
+	return discount, nil
+}
+
+// allocate spreads amount over the lines in proportion to what is left of
+// each line after its own discount, adding it to DiscountAmount. Leftover
+// rupiah go to the lines with the largest remainders, earliest line first,
+// so the result is deterministic and always sums to amount.
+func allocate(lines []models.TransactionDetail, amount int) {
+	base := 0
+	for _, d := range lines {
+		base += d.GrossAmount - d.DiscountAmount
+	}
+	if amount == 0 || base == 0 {
+		return
+	}
+
+	remainders := make([]int, len(lines))
+	allocated := 0
+	for i := range lines {
+		share := (lines[i].GrossAmount - lines[i].DiscountAmount) * amount
+		lines[i].DiscountAmount += share / base
+		remainders[i] = share % base
+		allocated += share / base
+	}
+
+	for left := amount - allocated; left > 0; left-- {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		lines[best].DiscountAmount++
+		remainders[best] = -1
+	}
+}
+
+// roundDiv divides a by b (both non-negative), rounding halves up.
+func roundDiv(a, b int) int {
+	return (a + b/2) / b
+}
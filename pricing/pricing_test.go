@@ -0,0 +1,171 @@
+package pricing
+
+import (
+	"errors"
+	"kasir-api/models"
+	"slices"
+	"testing"
+)
+
+func TestDiscountAmount(t *testing.T) {
+	c := &Calculator{MaxDiscountPercent: 50}
+
+	tests := []struct {
+		name     string
+		discount *models.Discount
+		amount   int
+		want     int
+		wantErr  bool
+	}{
+		{name: "none", discount: nil, amount: 1000, want: 0},
+		{name: "percent rounds half up", discount: &models.Discount{Type: models.DiscountTypePercent, Value: 10}, amount: 1005, want: 101},
+		{name: "percent over 100", discount: &models.Discount{Type: models.DiscountTypePercent, Value: 101}, amount: 1000, wantErr: true},
+		{name: "negative percent", discount: &models.Discount{Type: models.DiscountTypePercent, Value: -1}, amount: 1000, wantErr: true},
+		{name: "rounded percent over cap", discount: &models.Discount{Type: models.DiscountTypePercent, Value: 50}, amount: 999, wantErr: true},
+		{name: "fixed", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 300}, amount: 1000, want: 300},
+		{name: "fixed over amount", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 1200}, amount: 1000, wantErr: true},
+		{name: "negative fixed", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: -5}, amount: 1000, wantErr: true},
+		{name: "fixed over cap", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 600}, amount: 1000, wantErr: true},
+		{name: "unknown type", discount: &models.Discount{Type: "bogus", Value: 1}, amount: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.discountAmount(tt.discount, tt.amount)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("discountAmount() = %d, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("discountAmount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("discountAmount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name      string
+		gross     []int
+		discounts []int
+		amount    int
+		want      []int
+	}{
+		{name: "exact shares", gross: []int{1000, 3000}, discounts: []int{0, 0}, amount: 400, want: []int{100, 300}},
+		{name: "tied remainders go to the earliest line", gross: []int{1000, 1000, 1000}, discounts: []int{0, 0, 0}, amount: 100, want: []int{34, 33, 33}},
+		{name: "largest remainder wins", gross: []int{333, 667}, discounts: []int{0, 0}, amount: 50, want: []int{17, 33}},
+		{name: "spread over what is left after line discounts", gross: []int{1000, 200}, discounts: []int{200, 0}, amount: 100, want: []int{280, 20}},
+		{name: "nothing to spread", gross: []int{1000, 2000}, discounts: []int{10, 0}, amount: 0, want: []int{10, 0}},
+		{name: "fully discounted lines", gross: []int{500}, discounts: []int{500}, amount: 0, want: []int{500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]models.TransactionDetail, len(tt.gross))
+			before := 0
+			for i := range lines {
+				lines[i].GrossAmount = tt.gross[i]
+				lines[i].DiscountAmount = tt.discounts[i]
+				before += tt.discounts[i]
+			}
+
+			allocate(lines, tt.amount)
+
+			got := make([]int, len(lines))
+			after := 0
+			for i, d := range lines {
+				got[i] = d.DiscountAmount
+				after += d.DiscountAmount
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("discounts = %v, want %v", got, tt.want)
+			}
+			if after-before != tt.amount {
+				t.Errorf("allocated %d, want %d", after-before, tt.amount)
+			}
+		})
+	}
+}
+
+func TestPriceOrderDiscount(t *testing.T) {
+	products := map[int]*models.Product{
+		1: {ID: 1, Name: "A", Price: 1000},
+		2: {ID: 2, Name: "B", Price: 500},
+	}
+
+	tests := []struct {
+		name        string
+		maxDiscount int
+		req         models.CheckoutRequest
+		wantLines   []int
+		wantTotal   int
+		wantErr     bool
+	}{
+		{
+			name:        "order discount spread over lines",
+			maxDiscount: 100,
+			req: models.CheckoutRequest{
+				Items: []models.CheckoutItem{
+					{ProductID: 1, Quantity: 3},
+					{ProductID: 2, Quantity: 2, Discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 100}},
+				},
+				Discount: &models.Discount{Type: models.DiscountTypePercent, Value: 10},
+			},
+			wantLines: []int{300, 190},
+			wantTotal: 3510,
+		},
+		{
+			name:        "line and order discounts together over the cap",
+			maxDiscount: 10,
+			req: models.CheckoutRequest{
+				Items: []models.CheckoutItem{
+					{ProductID: 1, Quantity: 3, Discount: &models.Discount{Type: models.DiscountTypePercent, Value: 10}},
+					{ProductID: 2, Quantity: 2},
+				},
+				Discount: &models.Discount{Type: models.DiscountTypePercent, Value: 10},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalculator(Calculator{MaxDiscountPercent: tt.maxDiscount})
+			res, err := c.Price(tt.req, products)
+			if tt.wantErr {
+				var validationErr *models.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Price() error = %v, want a ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Price() error = %v", err)
+			}
+
+			got := make([]int, len(res.Details))
+			discount := 0
+			for i, d := range res.Details {
+				got[i] = d.DiscountAmount
+				discount += d.DiscountAmount
+				if d.Subtotal != d.GrossAmount-d.DiscountAmount {
+					t.Errorf("line %d subtotal = %d, want %d", i, d.Subtotal, d.GrossAmount-d.DiscountAmount)
+				}
+			}
+			if !slices.Equal(got, tt.wantLines) {
+				t.Errorf("line discounts = %v, want %v", got, tt.wantLines)
+			}
+			if res.DiscountAmount != discount {
+				t.Errorf("DiscountAmount = %d, want the line sum %d", res.DiscountAmount, discount)
+			}
+			if res.TotalAmount != tt.wantTotal {
+				t.Errorf("TotalAmount = %d, want %d", res.TotalAmount, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"slices"
+)
+
+const (
+	RoundingNone    = "none"
+	RoundingNearest = "nearest"
+	RoundingUp      = "up"
+	RoundingDown    = "down"
+)
+
+var RoundingStrategies = []string{RoundingNone, RoundingNearest, RoundingUp, RoundingDown}
+
+// ValidateRounding checks the cash rounding settings.
+func (c *Calculator) ValidateRounding() error {
+	if c.CashRounding == "" {
+		c.CashRounding = RoundingNone
+	}
+	if !slices.Contains(RoundingStrategies, c.CashRounding) {
+		return fmt.Errorf("cash rounding must be one of %v", RoundingStrategies)
+	}
+	if c.CashRounding != RoundingNone && c.CashRoundingIncrement <= 0 {
+		return fmt.Errorf("cash rounding increment must be greater than 0")
+	}
+	return nil
+}
+
+// Rounding returns how much the amount due changes when the cash part of
+// payments is rounded to CashRoundingIncrement. Card, QRIS and e-wallet
+// payments are taken as exact, so only what is left for cash is rounded,
+// and nothing is rounded when no cash is paid. With no payments at all the
+// whole total is assumed to be paid in cash, which is what a quote shows.
+func (c *Calculator) Rounding(total int, payments []models.CheckoutPayment) int {
+	if c.CashRounding == "" || c.CashRounding == RoundingNone {
+		return 0
+	}
+
+	cash := len(payments) == 0
+	nonCash := 0
+	for _, payment := range payments {
+		if payment.Method == models.PaymentMethodCash {
+			cash = true
+		} else {
+			nonCash += payment.Amount
+		}
+	}
+
+	due := total - nonCash
+	if !cash || due <= 0 {
+		return 0
+	}
+
+	inc := c.CashRoundingIncrement
+	var rounded int
+	switch c.CashRounding {
+	case RoundingNearest:
+		rounded = roundDiv(due, inc) * inc
+	case RoundingUp:
+		rounded = (due + inc - 1) / inc * inc
+	case RoundingDown:
+		rounded = due / inc * inc
+	}
+
+	return rounded - due
+}
@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	cash := func(amount int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: models.PaymentMethodCash, Amount: amount}
+	}
+	card := func(amount int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: models.PaymentMethodDebitCard, Amount: amount}
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		total    int
+		payments []models.CheckoutPayment
+		want     int
+	}{
+		{name: "none", mode: RoundingNone, total: 12345, payments: []models.CheckoutPayment{cash(20000)}, want: 0},
+		{name: "unset", mode: "", total: 12345, payments: []models.CheckoutPayment{cash(20000)}, want: 0},
+		{name: "nearest down", mode: RoundingNearest, total: 12345, payments: []models.CheckoutPayment{cash(20000)}, want: -45},
+		{name: "nearest half goes up", mode: RoundingNearest, total: 12350, payments: []models.CheckoutPayment{cash(20000)}, want: 50},
+		{name: "up", mode: RoundingUp, total: 12301, payments: []models.CheckoutPayment{cash(20000)}, want: 99},
+		{name: "up exact", mode: RoundingUp, total: 12300, payments: []models.CheckoutPayment{cash(20000)}, want: 0},
+		{name: "down", mode: RoundingDown, total: 12399, payments: []models.CheckoutPayment{cash(20000)}, want: -99},
+		{name: "no payments counts as cash", mode: RoundingDown, total: 12399, want: -99},
+		{name: "only the cash part is rounded", mode: RoundingUp, total: 12345, payments: []models.CheckoutPayment{card(5010), cash(8000)}, want: 65},
+		{name: "no cash", mode: RoundingUp, total: 12345, payments: []models.CheckoutPayment{card(12345)}, want: 0},
+		{name: "non-cash covers the total", mode: RoundingUp, total: 12345, payments: []models.CheckoutPayment{card(12345), cash(100)}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Calculator{CashRounding: tt.mode, CashRoundingIncrement: 100}
+			if got := c.Rounding(tt.total, tt.payments); got != tt.want {
+				t.Errorf("Rounding() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		increment int
+		wantMode  string
+		wantErr   bool
+	}{
+		{name: "unset defaults to none", mode: "", wantMode: RoundingNone},
+		{name: "none needs no increment", mode: RoundingNone, wantMode: RoundingNone},
+		{name: "nearest", mode: RoundingNearest, increment: 500, wantMode: RoundingNearest},
+		{name: "missing increment", mode: RoundingUp, wantErr: true},
+		{name: "negative increment", mode: RoundingDown, increment: -100, wantErr: true},
+		{name: "unknown mode", mode: "bankers", increment: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Calculator{CashRounding: tt.mode, CashRoundingIncrement: tt.increment}
+			err := c.ValidateRounding()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ValidateRounding() = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateRounding() error = %v", err)
+			}
+			if c.CashRounding != tt.wantMode {
+				t.Errorf("CashRounding = %q, want %q", c.CashRounding, tt.wantMode)
+			}
+		})
+	}
+}
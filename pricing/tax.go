@@ -0,0 +1,52 @@
+package pricing
+
+import (
+	"kasir-api/models"
+	"math"
+)
+
+// basisPoints converts a percentage such as 11 or 1.1 into hundredths of a
+// percent, so that tax can be computed with integer arithmetic only.
+func basisPoints(percent float64) int {
+	return int(math.Round(percent * 100))
+}
+
+// taxRate returns the rate that applies to p: its own rate, else its
+// category's (already folded into p.TaxRate by the repository), else the
+// store rate.
+func (c *Calculator) taxRate(p *models.Product) float64 {
+	if p.TaxRate != nil {
+		return *p.TaxRate
+	}
+	return c.TaxRate
+}
+
+// applyTax fills in the tax of every line and the transaction totals.
+//
+// Tax is worked out per line on the amount after discounts and rounded
+// half up to whole rupiah; the transaction tax is the sum of the line taxes.
+// With tax-inclusive prices the tax is carved out of the line amount,
+// otherwise it is added on top.
+func (c *Calculator) applyTax(res *models.Transaction, products map[int]*models.Product) {
+	res.Subtotal = 0
+	res.TaxAmount = 0
+	res.TotalAmount = 0
+
+	for i := range res.Details {
+		d := &res.Details[i]
+		d.TaxRate = c.taxRate(products[d.ProductID])
+		bp := basisPoints(d.TaxRate)
+
+		if c.PricesIncludeTax {
+			d.TaxAmount = d.Subtotal - roundDiv(d.Subtotal*10000, 10000+bp)
+			d.TotalAmount = d.Subtotal
+		} else {
+			d.TaxAmount = roundDiv(d.Subtotal*bp, 10000)
+			d.TotalAmount = d.Subtotal + d.TaxAmount
+		}
+
+		res.Subtotal += d.TotalAmount - d.TaxAmount
+		res.TaxAmount += d.TaxAmount
+		res.TotalAmount += d.TotalAmount
+	}
+}
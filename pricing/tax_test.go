@@ -0,0 +1,93 @@
+package pricing
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestBasisPoints(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    int
+	}{
+		{0, 0},
+		{11, 1100},
+		{1.1, 110},
+		{12.5, 1250},
+		{0.01, 1},
+	}
+
+	for _, tt := range tests {
+		if got := basisPoints(tt.percent); got != tt.want {
+			t.Errorf("basisPoints(%v) = %d, want %d", tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	rate := func(r float64) *float64 { return &r }
+
+	tests := []struct {
+		name         string
+		storeRate    float64
+		inclusive    bool
+		productRate  *float64
+		subtotal     int
+		wantTax      int
+		wantTotal    int
+		wantLineRate float64
+	}{
+		{name: "exclusive", storeRate: 11, subtotal: 10000, wantTax: 1100, wantTotal: 11100, wantLineRate: 11},
+		{name: "exclusive rounds half up", storeRate: 11, subtotal: 1005, wantTax: 111, wantTotal: 1116, wantLineRate: 11},
+		{name: "inclusive", storeRate: 11, inclusive: true, subtotal: 11100, wantTax: 1100, wantTotal: 11100, wantLineRate: 11},
+		{name: "inclusive carves tax out", storeRate: 11, inclusive: true, subtotal: 1000, wantTax: 99, wantTotal: 1000, wantLineRate: 11},
+		{name: "fractional rate", storeRate: 1.1, subtotal: 10000, wantTax: 110, wantTotal: 10110, wantLineRate: 1.1},
+		{name: "no tax", storeRate: 0, subtotal: 10000, wantTax: 0, wantTotal: 10000, wantLineRate: 0},
+		{name: "product rate overrides store", storeRate: 11, productRate: rate(0), subtotal: 10000, wantTax: 0, wantTotal: 10000, wantLineRate: 0},
+		{name: "product rate inclusive", storeRate: 0, inclusive: true, productRate: rate(10), subtotal: 5500, wantTax: 500, wantTotal: 5500, wantLineRate: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Calculator{TaxRate: tt.storeRate, PricesIncludeTax: tt.inclusive}
+			products := map[int]*models.Product{1: {ID: 1, TaxRate: tt.productRate}}
+			res := &models.Transaction{Details: []models.TransactionDetail{{ProductID: 1, Subtotal: tt.subtotal}}}
+
+			c.applyTax(res, products)
+
+			d := res.Details[0]
+			if d.TaxRate != tt.wantLineRate {
+				t.Errorf("line TaxRate = %v, want %v", d.TaxRate, tt.wantLineRate)
+			}
+			if d.TaxAmount != tt.wantTax {
+				t.Errorf("line TaxAmount = %d, want %d", d.TaxAmount, tt.wantTax)
+			}
+			if d.TotalAmount != tt.wantTotal {
+				t.Errorf("line TotalAmount = %d, want %d", d.TotalAmount, tt.wantTotal)
+			}
+			if res.TaxAmount != tt.wantTax || res.TotalAmount != tt.wantTotal || res.Subtotal != tt.wantTotal-tt.wantTax {
+				t.Errorf("totals = subtotal %d, tax %d, total %d; want %d, %d, %d",
+					res.Subtotal, res.TaxAmount, res.TotalAmount, tt.wantTotal-tt.wantTax, tt.wantTax, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestApplyTaxSumsLines(t *testing.T) {
+	c := &Calculator{TaxRate: 11}
+	products := map[int]*models.Product{1: {ID: 1}, 2: {ID: 2}}
+	res := &models.Transaction{Details: []models.TransactionDetail{
+		{ProductID: 1, Subtotal: 1005},
+		{ProductID: 2, Subtotal: 1005},
+	}}
+
+	c.applyTax(res, products)
+
+	// tiap baris dibulatkan sendiri: 2 x 111, bukan 221 dari 2010
+	if res.TaxAmount != 222 {
+		t.Errorf("TaxAmount = %d, want 222", res.TaxAmount)
+	}
+	if res.TotalAmount != 2232 {
+		t.Errorf("TotalAmount = %d, want 2232", res.TotalAmount)
+	}
+}
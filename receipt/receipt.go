@@ -0,0 +1,211 @@
+// Package receipt renders stored transactions as fixed-width receipts for
+// 58 mm and 80 mm thermal printers, either as plain text or as an ESC/POS
+// byte stream.
+package receipt
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/rupiah"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FormatText   = "text"
+	FormatESCPOS = "escpos"
+
+	// Width58mm and Width80mm are the usual characters per line of 58 mm
+	// and 80 mm printers with the default font.
+	Width58mm = 32
+	Width80mm = 48
+
+	MinWidth = 24
+	MaxWidth = 64
+)
+
+// Store is printed at the top and bottom of every receipt.
+type Store struct {
+	Name    string
+	Address string
+	Phone   string
+	Footer  string
+}
+
+type Printer struct {
+	Store        Store
+	DefaultWidth int
+}
+
+func NewPrinter(store Store, defaultWidth int) *Printer {
+	return &Printer{Store: store, DefaultWidth: defaultWidth}
+}
+
+// line is one row of a receipt. Rows are built once and then written out
+// either as plain text or with ESC/POS styling.
+type line struct {
+	text   string
+	center bool
+	bold   bool
+	large  bool
+}
+
+// layout lays the transaction out in rows of at most width characters.
+func (p *Printer) layout(t *models.Transaction, width int) []line {
+	lines := make([]line, 0)
+	add := func(text string) { lines = append(lines, line{text: text}) }
+	centered := func(text string) {
+		for _, l := range wrap(text, width) {
+			lines = append(lines, line{text: l, center: true})
+		}
+	}
+	separator := strings.Repeat("-", width)
+
+	if p.Store.Name != "" {
+		lines = append(lines, line{text: p.Store.Name, center: true, bold: true, large: true})
+	}
+	if p.Store.Address != "" {
+		centered(p.Store.Address)
+	}
+	if p.Store.Phone != "" {
+		centered(p.Store.Phone)
+	}
+	add(separator)
+
+	number := t.InvoiceNumber
+	if number == "" {
+		number = fmt.Sprintf("#%d", t.ID)
+	}
+	add(columns("No", number, width))
+	add(columns("Date", t.CreatedAt.Local().Format("02/01/2006 15:04"), width))
+	if t.Cashier != "" {
+		add(columns("Cashier", t.Cashier, width))
+	}
+	add(separator)
+
+	for _, d := range t.Details {
+		for _, l := range wrap(d.ProductName, width) {
+			add(l)
+		}
+
+		add(columns(fmt.Sprintf("  %d x %s", d.Quantity, rupiah.Format(d.UnitPrice)), rupiah.Format(d.GrossAmount), width))
+		if d.DiscountAmount != 0 {
+			add(columns("  Discount", "-"+rupiah.Format(d.DiscountAmount), width))
+		}
+	}
+	add(separator)
+
+	if t.DiscountAmount != 0 {
+		add(columns("Gross", rupiah.Format(t.GrossAmount), width))
+		add(columns("Discount", "-"+rupiah.Format(t.DiscountAmount), width))
+	}
+	add(columns("Subtotal", rupiah.Format(t.Subtotal), width))
+	if t.TaxAmount != 0 {
+		add(columns("Tax", rupiah.Format(t.TaxAmount), width))
+	}
+	if t.RoundingAmount != 0 {
+		add(columns("Total", rupiah.Format(t.TotalAmount), width))
+		add(columns("Rounding", rupiah.Format(t.RoundingAmount), width))
+	}
+	lines = append(lines, line{text: columns("TOTAL", rupiah.Format(t.TotalAmount+t.RoundingAmount), width), bold: true})
+
+	if len(t.Payments) > 0 {
+		add(separator)
+		for _, payment := range t.Payments {
+			add(columns(paymentLabel(payment.Method), rupiah.Format(payment.Amount), width))
+		}
+		add(columns("Change", rupiah.Format(t.ChangeAmount), width))
+	}
+
+	if t.PointsEarned != 0 {
+		add(columns("Points earned", strconv.Itoa(t.PointsEarned), width))
+	}
+
+	if t.Status != "" && t.Status != models.TransactionStatusCompleted {
+		add(separator)
+		lines = append(lines, line{text: strings.ToUpper(strings.ReplaceAll(t.Status, "_", " ")), center: true, bold: true})
+	}
+
+	if p.Store.Footer != "" {
+		add(separator)
+		centered(p.Store.Footer)
+	}
+
+	return lines
+}
+
+func paymentLabel(method string) string {
+	switch method {
+	case models.PaymentMethodCash:
+		return "Cash"
+	case models.PaymentMethodQRIS:
+		return "QRIS"
+	case models.PaymentMethodDebitCard:
+		return "Debit Card"
+	case models.PaymentMethodEWallet:
+		return "E-Wallet"
+	case models.PaymentMethodPoints:
+		return "Points"
+	case models.PaymentMethodOnAccount:
+		return "On Account"
+	}
+	return method
+}
+
+// columns puts left and right on one row of width characters, cutting the
+// left side short when both do not fit, and the right side too when it is
+// wider than the row on its own.
+func columns(left, right string, width int) string {
+	right = truncate(right, width)
+	space := width - utf8.RuneCountInString(right) - 1
+	if space < 0 {
+		space = 0
+	}
+	left = truncate(left, space)
+	return left + strings.Repeat(" ", width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right)) + right
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+func center(s string, width int) string {
+	s = truncate(s, width)
+	pad := (width - utf8.RuneCountInString(s)) / 2
+	return strings.Repeat(" ", pad) + s
+}
+
+// wrap breaks s into rows of at most width characters, on word boundaries
+// where possible.
+func wrap(s string, width int) []string {
+	rows := make([]string, 0, 1)
+	current := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if current != "" {
+				rows = append(rows, current)
+				current = ""
+			}
+			rows = append(rows, string([]rune(word)[:width]))
+			word = string([]rune(word)[width:])
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			rows = append(rows, current)
+			current = word
+		}
+	}
+	if current != "" || len(rows) == 0 {
+		rows = append(rows, current)
+	}
+	return rows
+}
@@ -0,0 +1,74 @@
+package receipt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  []string
+	}{
+		{name: "fits", s: "Kopi Susu", width: 10, want: []string{"Kopi Susu"}},
+		{name: "breaks on words", s: "Kopi Susu Gula Aren", width: 10, want: []string{"Kopi Susu", "Gula Aren"}},
+		{name: "collapses spaces", s: "  Kopi   Susu ", width: 10, want: []string{"Kopi Susu"}},
+		{name: "splits long words", s: "Supercalifragilistic", width: 8, want: []string{"Supercal", "ifragili", "stic"}},
+		{name: "long word after short one", s: "Es Supercalifragilistic", width: 8, want: []string{"Es", "Supercal", "ifragili", "stic"}},
+		{name: "counts runes, not bytes", s: "Café Crème", width: 5, want: []string{"Café", "Crème"}},
+		{name: "empty", s: "", width: 10, want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrap(tt.s, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("wrap(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right string
+		width       int
+		want        string
+	}{
+		{name: "right aligned", left: "Total", right: "10.500", width: 16, want: "Total     10.500"},
+		{name: "exact fit keeps one space", left: "Subtotal", right: "1.000", width: 14, want: "Subtotal 1.000"},
+		{name: "left cut short", left: "Points earned", right: "1.000", width: 12, want: "Points 1.000"},
+		{name: "right wider than the row", left: "No", right: "INV-2026-0001", width: 10, want: "INV-2026-0"},
+		{name: "runes", left: "Crème", right: "5", width: 8, want: "Crème  5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columns(tt.left, tt.right, tt.width); got != tt.want {
+				t.Errorf("columns(%q, %q, %d) = %q, want %q", tt.left, tt.right, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCenter(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "Toko", width: 10, want: "   Toko"},
+		{s: "Toko", width: 9, want: "  Toko"},
+		{s: "Toko Kelontong", width: 8, want: "Toko Kel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := center(tt.s, tt.width); got != tt.want {
+				t.Errorf("center(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package receipt
+
+import (
+	"bytes"
+	"kasir-api/models"
+	"strings"
+)
+
+// ESC/POS commands used by ESCPOS.
+var (
+	escInit        = []byte{0x1b, '@'}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escSizeLarge   = []byte{0x1d, '!', 0x11}
+	escSizeNormal  = []byte{0x1d, '!', 0x00}
+	escFeedAndCut  = []byte{0x1b, 'd', 4, 0x1d, 'V', 66, 0}
+)
+
+// Text renders t as plain fixed-width text. A width of 0 uses the
+// printer's default width.
+func (p *Printer) Text(t *models.Transaction, width int) string {
+	width = p.width(width)
+
+	var b strings.Builder
+	for _, l := range p.layout(t, width) {
+		if l.center {
+			b.WriteString(center(l.text, width))
+		} else {
+			b.WriteString(l.text)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ESCPOS renders t as a raw ESC/POS byte stream, ready to be sent to a
+// thermal printer as is. A width of 0 uses the printer's default width.
+func (p *Printer) ESCPOS(t *models.Transaction, width int) []byte {
+	width = p.width(width)
+
+	var b bytes.Buffer
+	b.Write(escInit)
+	for _, l := range p.layout(t, width) {
+		text := l.text
+		if l.large {
+			// double-width text fits half as many characters
+			text = truncate(text, width/2)
+		}
+
+		if l.center {
+			b.Write(escAlignCenter)
+		}
+		if l.bold {
+			b.Write(escBoldOn)
+		}
+		if l.large {
+			b.Write(escSizeLarge)
+		}
+
+		b.Write(ascii(text))
+		b.WriteByte('\n')
+
+		if l.large {
+			b.Write(escSizeNormal)
+		}
+		if l.bold {
+			b.Write(escBoldOff)
+		}
+		if l.center {
+			b.Write(escAlignLeft)
+		}
+	}
+	b.Write(escFeedAndCut)
+	return b.Bytes()
+}
+
+func (p *Printer) width(width int) int {
+	if width == 0 {
+		width = p.DefaultWidth
+	}
+	return min(max(width, MinWidth), MaxWidth)
+}
+
+// ascii replaces everything outside printable ASCII, which is the only part
+// every printer code page agrees on.
+func ascii(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	return out
+}
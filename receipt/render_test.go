@@ -0,0 +1,93 @@
+package receipt
+
+import (
+	"bytes"
+	"kasir-api/models"
+	"testing"
+	"time"
+)
+
+func testTransaction() *models.Transaction {
+	return &models.Transaction{
+		InvoiceNumber: "INV-001",
+		CreatedAt:     time.Date(2026, 1, 2, 15, 4, 0, 0, time.Local),
+		Cashier:       "Budi",
+		Status:        models.TransactionStatusCompleted,
+		Details: []models.TransactionDetail{
+			{ProductName: "Kopi Susu", Quantity: 2, UnitPrice: 15000, GrossAmount: 30000, DiscountAmount: 3000},
+		},
+		GrossAmount:    30000,
+		DiscountAmount: 3000,
+		Subtotal:       27000,
+		TotalAmount:    27000,
+		ChangeAmount:   23000,
+		Payments:       []models.Payment{{Method: models.PaymentMethodCash, Amount: 50000}},
+	}
+}
+
+func TestText(t *testing.T) {
+	p := NewPrinter(Store{Name: "Toko Maju", Address: "Jl. Merdeka 1", Phone: "0812", Footer: "Terima kasih"}, Width58mm)
+
+	want := `           Toko Maju
+         Jl. Merdeka 1
+              0812
+--------------------------------
+No                       INV-001
+Date            02/01/2026 15:04
+Cashier                     Budi
+--------------------------------
+Kopi Susu
+  2 x 15.000              30.000
+  Discount                -3.000
+--------------------------------
+Gross                     30.000
+Discount                  -3.000
+Subtotal                  27.000
+TOTAL                     27.000
+--------------------------------
+Cash                      50.000
+Change                    23.000
+--------------------------------
+          Terima kasih
+`
+
+	if got := p.Text(testTransaction(), 0); got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+	p := NewPrinter(Store{Name: "Toko Maju"}, Width58mm)
+	tr := &models.Transaction{
+		ID:          7,
+		CreatedAt:   time.Date(2026, 1, 2, 15, 4, 0, 0, time.Local),
+		Details:     []models.TransactionDetail{{ProductName: "Café", Quantity: 1, UnitPrice: 5000, GrossAmount: 5000}},
+		GrossAmount: 5000,
+		Subtotal:    5000,
+		TotalAmount: 5000,
+		Status:      models.TransactionStatusVoided,
+	}
+
+	sep := "------------------------\n"
+	var want bytes.Buffer
+	want.WriteString("\x1b@")
+	// nama toko: tengah, tebal, besar
+	want.WriteString("\x1ba\x01\x1bE\x01\x1d!\x11Toko Maju\n\x1d!\x00\x1bE\x00\x1ba\x00")
+	want.WriteString(sep)
+	want.WriteString("No                    #7\n")
+	want.WriteString("Date    02/01/2026 15:04\n")
+	want.WriteString(sep)
+	want.WriteString("Caf?\n")
+	want.WriteString("  1 x 5.000        5.000\n")
+	want.WriteString(sep)
+	want.WriteString("Subtotal           5.000\n")
+	want.WriteString("\x1bE\x01TOTAL              5.000\n\x1bE\x00")
+	want.WriteString(sep)
+	want.WriteString("\x1ba\x01\x1bE\x01VOIDED\n\x1bE\x00\x1ba\x00")
+	want.WriteString("\x1bd\x04\x1dVB\x00")
+
+	// lebar di bawah minimum dinaikkan ke MinWidth
+	if got := p.ESCPOS(tr, 10); !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("ESCPOS() =\n%q\nwant\n%q", got, want.Bytes())
+	}
+}
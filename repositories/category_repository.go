@@ -15,7 +15,7 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 }
 
 func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := "SELECT id, name FROM categories"
+	query := "SELECT id, name, tax_rate FROM categories"
 	rows, err := repo.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -25,7 +25,7 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	categories := make([]models.Category, 0)
 	for rows.Next() {
 		var c models.Category
-		err := rows.Scan(&c.ID, &c.Name)
+		err := rows.Scan(&c.ID, &c.Name, &c.TaxRate)
 		if err != nil {
 			return nil, err
 		}
@@ -36,17 +36,17 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 }
 
 func (repo *CategoryRepository) Create(category *models.Category) error {
-	query := "INSERT INTO categories (name) VALUES ($1) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name).Scan(&category.ID)
+	query := "INSERT INTO categories (name, tax_rate) VALUES ($1, $2) RETURNING id"
+	err := repo.db.QueryRow(query, category.Name, category.TaxRate).Scan(&category.ID)
 	return err
 }
 
 // GetByID - ambil categories by ID
 func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
-	query := "SELECT id, name FROM categories WHERE id = $1"
+	query := "SELECT id, name, tax_rate FROM categories WHERE id = $1"
 
 	var p models.Category
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name)
+	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.TaxRate)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("Category is not found")
 	}
@@ -58,8 +58,8 @@ func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 }
 
 func (repo *CategoryRepository) Update(category *models.Category) error {
-	query := "UPDATE categories SET name = $1 WHERE id = $2"
-	result, err := repo.db.Exec(query, category.Name, category.ID)
+	query := "UPDATE categories SET name = $1, tax_rate = $2 WHERE id = $3"
+	result, err := repo.db.Exec(query, category.Name, category.TaxRate, category.ID)
 	if err != nil {
 		return err
 	}
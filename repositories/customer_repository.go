@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// customerQuery selects a customer with the amount they still owe.
+const customerQuery = `
+	SELECT c.id, c.name, c.phone, c.email, c.points, c.credit_limit,
+		COALESCE((SELECT SUM(r.amount - r.paid_amount - r.credited_amount) FROM receivables r WHERE r.customer_id = c.id), 0),
+		c.created_at
+	FROM customers c`
+
+// GetAll returns customers whose name contains search or whose phone
+// contains phone, the search in normalised form, or every customer when
+// search is empty. phone is ignored when it is empty, e.g. for a name.
+func (repo *CustomerRepository) GetAll(search, phone string) ([]models.Customer, error) {
+	query := customerQuery
+
+	var args []any
+	if search != "" {
+		query += ` WHERE c.name ILIKE $1 ESCAPE '\'`
+		args = append(args, "%"+likeEscaper.Replace(search)+"%")
+		if phone != "" {
+			query += " OR c.phone LIKE $2"
+			args = append(args, "%"+phone+"%")
+		}
+	}
+	query += " ORDER BY c.name, c.id"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		var c models.Customer
+		err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Points, &c.CreditLimit, &c.Outstanding, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+
+	return customers, rows.Err()
+}
+
+func (repo *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	return repo.getBy("id", id)
+}
+
+// GetByPhone looks a customer up by their normalised phone number.
+func (repo *CustomerRepository) GetByPhone(phone string) (*models.Customer, error) {
+	return repo.getBy("phone", phone)
+}
+
+func (repo *CustomerRepository) getBy(column string, value any) (*models.Customer, error) {
+	var c models.Customer
+	err := repo.db.QueryRow(customerQuery+" WHERE c."+column+" = $1", value).
+		Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Points, &c.CreditLimit, &c.Outstanding, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Customer"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (repo *CustomerRepository) Create(customer *models.Customer) error {
+	err := repo.db.QueryRow(
+		"INSERT INTO customers (name, phone, email, credit_limit) VALUES ($1, $2, $3, $4) RETURNING id, points, created_at",
+		customer.Name, customer.Phone, customer.Email, customer.CreditLimit,
+	).Scan(&customer.ID, &customer.Points, &customer.CreatedAt)
+	return phoneTaken(err)
+}
+
+func (repo *CustomerRepository) Update(customer *models.Customer) error {
+	err := repo.db.QueryRow(
+		`UPDATE customers c SET name = $1, phone = $2, email = $3, credit_limit = $4 WHERE id = $5
+		RETURNING points, COALESCE((SELECT SUM(r.amount - r.paid_amount - r.credited_amount) FROM receivables r WHERE r.customer_id = c.id), 0), created_at`,
+		customer.Name, customer.Phone, customer.Email, customer.CreditLimit, customer.ID,
+	).Scan(&customer.Points, &customer.Outstanding, &customer.CreatedAt)
+	if err == sql.ErrNoRows {
+		return &models.NotFoundError{Resource: "Customer"}
+	}
+	return phoneTaken(err)
+}
+
+// phoneTaken turns a unique violation on the phone number into a
+// validation error.
+func phoneTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return &models.ValidationError{
+			Message: "invalid customer",
+			Fields:  []models.FieldError{{Field: "phone", Message: "phone is already registered"}},
+		}
+	}
+	return err
+}
+
+// GetPoints returns a customer's point balance and ledger, newest first.
+func (repo *CustomerRepository) GetPoints(customerID int) (*models.PointsHistory, error) {
+	customer, err := repo.GetByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT id, customer_id, type, points, balance_after, transaction_id, refund_id, created_at
+		FROM customer_points
+		WHERE customer_id = $1
+		ORDER BY id DESC
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := &models.PointsHistory{
+		CustomerID: customer.ID,
+		Balance:    customer.Points,
+		Entries:    make([]models.PointsEntry, 0),
+	}
+	for rows.Next() {
+		var e models.PointsEntry
+		err := rows.Scan(&e.ID, &e.CustomerID, &e.Type, &e.Points, &e.BalanceAfter, &e.TransactionID, &e.RefundID, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history.Entries = append(history.Entries, e)
+	}
+
+	return history, rows.Err()
+}
+
+// lockCustomer locks a customer row and returns their point balance.
+func lockCustomer(tx *sql.Tx, id int) (int, error) {
+	var points int
+	err := tx.QueryRow("SELECT points FROM customers WHERE id = $1 FOR UPDATE", id).Scan(&points)
+	if err == sql.ErrNoRows {
+		return 0, &models.NotFoundError{Resource: "Customer"}
+	}
+	return points, err
+}
+
+// addPoints changes a customer's balance by points and writes the ledger
+// entry. The customer must already be locked.
+func addPoints(tx *sql.Tx, customerID int, entryType string, points int, transactionID, refundID *int) error {
+	if points == 0 {
+		return nil
+	}
+
+	var balance int
+	err := tx.QueryRow("UPDATE customers SET points = points + $1 WHERE id = $2 RETURNING points", points, customerID).Scan(&balance)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO customer_points (customer_id, type, points, balance_after, transaction_id, refund_id) VALUES ($1, $2, $3, $4, $5, $6)",
+		customerID, entryType, points, balance, transactionID, refundID,
+	)
+	return err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+)
+
+// ErrIdempotencyKeyTaken is returned when another request stored a result
+// for the same idempotency key first.
+var ErrIdempotencyKeyTaken = errors.New("idempotency key already used")
+
+// expiredKeysBatch is how many expired keys a checkout deletes on its way,
+// so the table stays small without a separate cleanup job.
+const expiredKeysBatch = 100
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Get returns the stored result for key, or nil if the key is unknown or expired.
+func (repo *IdempotencyRepository) Get(key string) (*models.IdempotencyKey, error) {
+	query := "SELECT key, request_hash, expires_at, response FROM idempotency_keys WHERE key = $1 AND expires_at > NOW()"
+
+	var k models.IdempotencyKey
+	var response []byte
+	err := repo.db.QueryRow(query, key).Scan(&k.Key, &k.RequestHash, &k.ExpiresAt, &response)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(response, &k.Response); err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// saveIdempotencyKey stores the checkout result inside the checkout's own
+// database transaction. An expired key may be reused; a live one may not.
+func saveIdempotencyKey(tx *sql.Tx, key *models.IdempotencyKey, transaction *models.Transaction) error {
+	response, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO idempotency_keys (key, request_hash, transaction_id, response, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			transaction_id = EXCLUDED.transaction_id,
+			response = EXCLUDED.response,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+	`, key.Key, key.RequestHash, transaction.ID, response, key.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrIdempotencyKeyTaken
+	}
+
+	// sekalian buang key yang sudah kedaluwarsa; yang lagi dikunci checkout
+	// lain dilewati supaya nggak saling tunggu
+	_, err = tx.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE key IN (
+			SELECT key FROM idempotency_keys
+			WHERE expires_at <= NOW()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, expiredKeysBatch)
+	return err
+}
@@ -0,0 +1,423 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+type OrderRepository struct {
+	db           *sql.DB
+	transactions *TransactionRepository
+}
+
+func NewOrderRepository(db *sql.DB, transactions *TransactionRepository) *OrderRepository {
+	return &OrderRepository{db: db, transactions: transactions}
+}
+
+// Create opens a new order with the given items.
+func (repo *OrderRepository) Create(req models.OrderRequest) (*models.Order, error) {
+	var id int
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := checkProducts(tx, req.Items, "items[%d].product_id"); err != nil {
+			return err
+		}
+
+		err := tx.QueryRow(
+			"INSERT INTO orders (label, cashier) VALUES ($1, $2) RETURNING id",
+			req.Label, req.Cashier,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range req.Items {
+			if _, err := insertOrderItem(tx, id, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// GetAll returns the orders with the given status, oldest first.
+func (repo *OrderRepository) GetAll(status string) ([]models.Order, error) {
+	rows, err := repo.db.Query(
+		"SELECT id, label, cashier, status, transaction_id, created_at, updated_at FROM orders WHERE status = $1 ORDER BY created_at, id",
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var o models.Order
+		err := rows.Scan(&o.ID, &o.Label, &o.Cashier, &o.Status, &o.TransactionID, &o.CreatedAt, &o.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+		ids = append(ids, o.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := repo.getItems(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].Items = items[orders[i].ID]
+	}
+
+	return orders, nil
+}
+
+func (repo *OrderRepository) GetByID(id int) (*models.Order, error) {
+	var o models.Order
+	err := repo.db.QueryRow(
+		"SELECT id, label, cashier, status, transaction_id, created_at, updated_at FROM orders WHERE id = $1",
+		id,
+	).Scan(&o.ID, &o.Label, &o.Cashier, &o.Status, &o.TransactionID, &o.CreatedAt, &o.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Order"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := repo.getItems([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	o.Items = items[id]
+
+	return &o, nil
+}
+
+// Update changes the label and cashier of an open order.
+func (repo *OrderRepository) Update(id int, label, cashier string) (*models.Order, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenOrder(tx, id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("UPDATE orders SET label = $1, cashier = $2, updated_at = NOW() WHERE id = $3", label, cashier, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// AddItem adds a line to an open order.
+func (repo *OrderRepository) AddItem(orderID int, item models.OrderItemRequest) (*models.Order, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenOrder(tx, orderID); err != nil {
+			return err
+		}
+		if err := checkProducts(tx, []models.OrderItemRequest{item}, "product_id"); err != nil {
+			return err
+		}
+
+		_, err := insertOrderItem(tx, orderID, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(orderID)
+}
+
+// UpdateItem replaces a line of an open order.
+func (repo *OrderRepository) UpdateItem(orderID, itemID int, item models.OrderItemRequest) (*models.Order, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenOrder(tx, orderID); err != nil {
+			return err
+		}
+		if err := checkProducts(tx, []models.OrderItemRequest{item}, "product_id"); err != nil {
+			return err
+		}
+
+		discountType, discountValue := discountColumns(item.Discount)
+		result, err := tx.Exec(
+			"UPDATE order_items SET product_id = $1, quantity = $2, discount_type = $3, discount_value = $4, note = $5 WHERE id = $6 AND order_id = $7",
+			item.ProductID, item.Quantity, discountType, discountValue, item.Note, itemID, orderID,
+		)
+		if err != nil {
+			return err
+		}
+		return touchOrderItem(tx, result, orderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(orderID)
+}
+
+// DeleteItem removes a line from an open order.
+func (repo *OrderRepository) DeleteItem(orderID, itemID int) (*models.Order, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenOrder(tx, orderID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec("DELETE FROM order_items WHERE id = $1 AND order_id = $2", itemID, orderID)
+		if err != nil {
+			return err
+		}
+		return touchOrderItem(tx, result, orderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(orderID)
+}
+
+// Cancel abandons an open order. Nothing was taken from stock yet, so
+// there is nothing to put back.
+func (repo *OrderRepository) Cancel(id int) (*models.Order, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenOrder(tx, id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2", models.OrderStatusCancelled, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// Settle turns an open order into a transaction through the normal
+// checkout, and marks the order settled in the same database transaction.
+// normalise gets the order's lines as checkout items, so they are merged and
+// capped exactly like the items of a direct checkout.
+func (repo *OrderRepository) Settle(id int, req models.SettleOrderRequest, normalise func([]models.CheckoutItem) ([]models.CheckoutItem, error)) (*models.Transaction, error) {
+	var (
+		res *models.Transaction
+	)
+
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		// kunci order biar nggak bisa dibayar dua kali atau diubah pas lagi dibayar
+		if err := lockOpenOrder(tx, id); err != nil {
+			return err
+		}
+
+		items, err := getOrderItems(tx, []int{id})
+		if err != nil {
+			return err
+		}
+		if len(items[id]) == 0 {
+			return &models.ValidationError{Message: "order has no items"}
+		}
+
+		checkout := models.CheckoutRequest{
+			OutletCode: req.OutletCode,
+			ShiftID:    req.ShiftID,
+			CustomerID: req.CustomerID,
+			Cashier:    req.Cashier,
+			Discount:   req.Discount,
+			Payments:   req.Payments,
+		}
+		for _, item := range items[id] {
+			checkout.Items = append(checkout.Items, models.CheckoutItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Discount:  item.Discount,
+			})
+		}
+		checkout.Items, err = normalise(checkout.Items)
+		if err != nil {
+			return err
+		}
+
+		res, err = repo.transactions.createTransaction(tx, checkout, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE orders SET status = $1, transaction_id = $2, updated_at = NOW() WHERE id = $3",
+			models.OrderStatusSettled, res.ID, id,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// lockOpenOrder locks an order row and checks that it can still be changed.
+func lockOpenOrder(tx *sql.Tx, id int) error {
+	var status string
+	err := tx.QueryRow("SELECT status FROM orders WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return &models.NotFoundError{Resource: "Order"}
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != models.OrderStatusOpen {
+		return &models.ValidationError{Message: fmt.Sprintf("order is already %s", status)}
+	}
+	return nil
+}
+
+// touchOrderItem reports a missing line as not found and otherwise bumps
+// the order's updated_at.
+func touchOrderItem(tx *sql.Tx, result sql.Result, orderID int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &models.NotFoundError{Resource: "Order item"}
+	}
+
+	_, err = tx.Exec("UPDATE orders SET updated_at = NOW() WHERE id = $1", orderID)
+	return err
+}
+
+// checkProducts fails with a validation error for every item whose product
+// does not exist. field is formatted with the item's index.
+func checkProducts(tx *sql.Tx, items []models.OrderItemRequest, field string) error {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	rows, err := tx.Query("SELECT p.id, p.name, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id) FROM products p WHERE p.id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// produk induk yang punya varian nggak bisa dijual langsung
+	found := make(map[int]bool, len(ids))
+	parents := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		var hasVariants bool
+		if err := rows.Scan(&id, &name, &hasVariants); err != nil {
+			return err
+		}
+		found[id] = true
+		if hasVariants {
+			parents[id] = name
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	for i, item := range items {
+		if !found[item.ProductID] {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf(field, i),
+				Message: fmt.Sprintf("product id %d not found", item.ProductID),
+			})
+		} else if name, ok := parents[item.ProductID]; ok {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf(field, i),
+				Message: fmt.Sprintf("%s comes in variants; sell one of its variants instead", name),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &models.ValidationError{Message: "invalid order items", Fields: fieldErrors}
+	}
+	return nil
+}
+
+func insertOrderItem(tx *sql.Tx, orderID int, item models.OrderItemRequest) (int, error) {
+	discountType, discountValue := discountColumns(item.Discount)
+
+	var id int
+	err := tx.QueryRow(
+		"INSERT INTO order_items (order_id, product_id, quantity, discount_type, discount_value, note) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		orderID, item.ProductID, item.Quantity, discountType, discountValue, item.Note,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("UPDATE orders SET updated_at = NOW() WHERE id = $1", orderID)
+	return id, err
+}
+
+// discountColumns splits a discount into its nullable columns.
+func discountColumns(d *models.Discount) (discountType, discountValue any) {
+	if d == nil {
+		return nil, nil
+	}
+	return d.Type, d.Value
+}
+
+func (repo *OrderRepository) getItems(orderIDs []int) (map[int][]models.OrderItem, error) {
+	return getOrderItems(repo.db, orderIDs)
+}
+
+// getOrderItems loads the lines of the given orders, keyed by order ID.
+func getOrderItems(q queryer, orderIDs []int) (map[int][]models.OrderItem, error) {
+	items := make(map[int][]models.OrderItem, len(orderIDs))
+	for _, id := range orderIDs {
+		items[id] = make([]models.OrderItem, 0)
+	}
+	if len(orderIDs) == 0 {
+		return items, nil
+	}
+
+	rows, err := q.Query(`
+		SELECT oi.id, oi.order_id, oi.product_id, COALESCE(p.name, ''), COALESCE(p.price, 0), oi.quantity,
+			oi.discount_type, oi.discount_value, oi.note
+		FROM order_items oi
+		LEFT JOIN products p ON p.id = oi.product_id
+		WHERE oi.order_id = ANY($1)
+		ORDER BY oi.id
+	`, pq.Array(orderIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.OrderItem
+		var discountType sql.NullString
+		var discountValue sql.NullInt64
+		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.ProductName, &item.Price, &item.Quantity, &discountType, &discountValue, &item.Note)
+		if err != nil {
+			return nil, err
+		}
+		if discountType.Valid {
+			item.Discount = &models.Discount{Type: discountType.String, Value: int(discountValue.Int64)}
+		}
+		items[item.OrderID] = append(items[item.OrderID], item)
+	}
+
+	return items, rows.Err()
+}
@@ -2,98 +2,683 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"kasir-api/barcode"
 	"kasir-api/models"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type ProductRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	internal *barcode.InternalRange
+}
+
+func NewProductRepository(db *sql.DB, internal *barcode.InternalRange) *ProductRepository {
+	return &ProductRepository{db: db, internal: internal}
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+// productColumns selects a product with its SKU, barcodes and variant
+// details.
+const productColumns = `p.id, p.name, COALESCE(p.sku, ''),
+	ARRAY(SELECT b.barcode FROM product_barcodes b WHERE b.product_id = p.id ORDER BY b.barcode),
+	p.price, p.stock, p.allow_backorder, p.tax_rate, p.category_id,
+	p.parent_id, p.options, p.variant_options, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)`
+
+func scanProduct(row rowScanner, extra ...any) (*models.Product, error) {
+	var p models.Product
+	var barcodes pq.StringArray
+	var options, variantOptions []byte
+	dest := append([]any{
+		&p.ID, &p.Name, &p.SKU, &barcodes, &p.Price, &p.Stock, &p.AllowBackorder, &p.TaxRate, &p.CategoryID,
+		&p.ParentID, &options, &variantOptions, &p.HasVariants,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	p.Barcodes = append(make([]string, 0, len(barcodes)), barcodes...)
+
+	if err := json.Unmarshal(options, &p.Options); err != nil {
+		return nil, err
+	}
+	if len(p.Options) == 0 {
+		p.Options = nil
+	}
+	if err := json.Unmarshal(variantOptions, &p.VariantOptions); err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
-func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
-	query := "SELECT id, name, price, stock, category_id FROM products"
+// productSortColumns maps the sort keys a client may ask for to columns.
+// Only these ever end up in ORDER BY.
+var productSortColumns = map[string]string{
+	models.ProductSortName:  "p.name",
+	models.ProductSortPrice: "p.price",
+	models.ProductSortStock: "p.stock",
+	models.ProductSortID:    "p.id",
+}
+
+// productCursor is where the previous page stopped: the sort value and id
+// of its last product. It is sent to clients base64 encoded.
+type productCursor struct {
+	Sort  string          `json:"s"`
+	Desc  bool            `json:"d,omitempty"`
+	Value json.RawMessage `json:"v"`
+	ID    int             `json:"id"`
+}
+
+// GetAll returns one page of products matching filter with the total number
+// of matches. Pages are keyset based: the next page starts after the last
+// product of this one, so rows added or removed meanwhile do not shift it.
+func (repo *ProductRepository) GetAll(filter models.ProductFilter) (*models.ProductPage, error) {
+	column, ok := productSortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", filter.Sort)
+	}
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Name != "" {
+		addCondition(`p.name ILIKE $%d ESCAPE '\'`, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if filter.CategoryID != 0 {
+		addCondition("p.category_id = $%d", filter.CategoryID)
+	}
+	if filter.MinPrice != nil {
+		addCondition("p.price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition("p.price <= $%d", *filter.MaxPrice)
+	}
+	switch filter.Stock {
+	case models.StockIn:
+		conditions = append(conditions, "p.stock > 0")
+	case models.StockOut:
+		conditions = append(conditions, "p.stock <= 0")
+	case models.StockLow:
+		addCondition("p.stock > 0 AND p.stock <= $%d", filter.LowStockThreshold)
+	}
 
-	var args []interface{}
-	if name != "" {
-		query += " WHERE name ILIKE $1"
-		args = append(args, "%"+name+"%")
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	// count dan halaman pakai FROM yang sama biar totalnya cocok
+	from := " FROM products p JOIN categories c ON p.category_id = c.id"
+
+	page := &models.ProductPage{Products: make([]models.Product, 0)}
+	err := repo.db.QueryRow("SELECT COUNT(*)"+from+where, args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, after := "ASC", ">"
+	if filter.Desc {
+		direction, after = "DESC", "<"
+	}
+
+	if filter.Cursor != "" {
+		value, id, err := decodeProductCursor(filter.Cursor, filter.Sort, filter.Desc)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value, id)
+		condition := fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", column, after, len(args)-1, len(args))
+		if where == "" {
+			where = " WHERE " + condition
+		} else {
+			where += " AND " + condition
+		}
+	}
+
+	// ambil satu baris lebih buat tahu masih ada halaman berikutnya
+	args = append(args, filter.Limit+1)
+	query := fmt.Sprintf(`
+	SELECT %s, c.name%s%s
+	ORDER BY %s %s, p.id %s
+	LIMIT $%d`, productColumns, from, where, column, direction, direction, len(args))
+
 	rows, err := repo.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	for rows.Next() {
+		var categoryName string
+		p, err := scanProduct(rows, &categoryName)
+		if err != nil {
+			return nil, err
+		}
+		p.CategoryName = categoryName
+		page.Products = append(page.Products, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Products) > filter.Limit {
+		page.Products = page.Products[:filter.Limit]
+		page.NextCursor = encodeProductCursor(page.Products[filter.Limit-1], filter.Sort, filter.Desc)
+	}
+
+	return page, nil
+}
+
+func encodeProductCursor(last models.Product, sort string, desc bool) string {
+	var value any
+	switch sort {
+	case models.ProductSortName:
+		value = last.Name
+	case models.ProductSortPrice:
+		value = last.Price
+	case models.ProductSortStock:
+		value = last.Stock
+	default:
+		value = last.ID
+	}
+
+	raw, _ := json.Marshal(value)
+	cursor, _ := json.Marshal(productCursor{Sort: sort, Desc: desc, Value: raw, ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+func decodeProductCursor(s, sort string, desc bool) (any, int, error) {
+	invalid := &models.ValidationError{
+		Message: "invalid cursor",
+		Fields:  []models.FieldError{{Field: "cursor", Message: "cursor is not valid for this sort and order; start again from the first page"}},
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	var cursor productCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort || cursor.Desc != desc {
+		return nil, 0, invalid
+	}
+
+	if sort == models.ProductSortName {
+		var name string
+		if err := json.Unmarshal(cursor.Value, &name); err != nil {
+			return nil, 0, invalid
+		}
+		return name, cursor.ID, nil
+	}
+	var n int
+	if err := json.Unmarshal(cursor.Value, &n); err != nil {
+		return nil, 0, invalid
+	}
+	return n, cursor.ID, nil
+}
+
+// searchThreshold is how close a word of a product or category name must
+// be to the query to count as a match, from 0 to 1. It is below the pg_trgm
+// default of 0.6 so that short queries with a typo still find something.
+const searchThreshold = "0.4"
+
+// Search finds products whose name, category, SKU or barcode matches q,
+// best match first. q is lowercased; code is q normalised as a barcode, or
+// empty when it is not one. A product also matches when every word of q
+// appears somewhere in its name, SKU or category, in any order.
+func (repo *ProductRepository) Search(q, code string, limit int) ([]models.ProductMatch, error) {
+	words := strings.Fields(q)
+	patterns := make([]string, len(words))
+	for i, word := range words {
+		patterns[i] = "%" + likeEscaper.Replace(word) + "%"
+	}
+
+	query := `
+	SELECT ` + productColumns + `, c.name,
+		CASE WHEN lower(p.sku) = $1 OR p.id IN (SELECT b.product_id FROM product_barcodes b WHERE b.barcode = $2) THEN 2 ELSE 0 END
+		+ CASE WHEN lower(p.name) LIKE $3 THEN 0.5 ELSE 0 END
+		+ CASE WHEN lower(p.name || ' ' || COALESCE(p.sku, '') || ' ' || c.name) LIKE ALL($4) THEN 0.5 ELSE 0 END
+		+ GREATEST(word_similarity($1, lower(p.name)), similarity($1, lower(p.name)), word_similarity($1, lower(c.name)) * 0.6) AS score
+	FROM products p
+	JOIN categories c ON p.category_id = c.id
+	WHERE $1 <% lower(p.name)
+		OR $1 <% lower(c.name)
+		OR lower(p.sku) = $1
+		OR p.id IN (SELECT b.product_id FROM product_barcodes b WHERE b.barcode = $2)
+		OR lower(p.name || ' ' || COALESCE(p.sku, '') || ' ' || c.name) LIKE ALL($4)
+	ORDER BY score DESC, p.name, p.id
+	LIMIT $5`
+
+	matches := make([]models.ProductMatch, 0)
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		// threshold cuma berlaku di transaksi ini
+		if _, err := tx.Exec("SET LOCAL pg_trgm.word_similarity_threshold = " + searchThreshold); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(query, q, code, likeEscaper.Replace(q)+"%", pq.Array(patterns), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var categoryName string
+			var score float64
+			p, err := scanProduct(rows, &categoryName, &score)
+			if err != nil {
+				return err
+			}
+			p.CategoryName = categoryName
+			matches = append(matches, models.ProductMatch{Product: *p, Score: score})
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// likeEscaper escapes the LIKE wildcards in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetForLabels returns the products picked by filter, ordered by name as
+// they are laid out on the label sheets.
+func (repo *ProductRepository) GetForLabels(filter models.LabelFilter) ([]models.Product, error) {
+	query := "SELECT " + productColumns + " FROM products p WHERE "
+	var arg any
+	switch {
+	case len(filter.IDs) > 0:
+		query += "p.id = ANY($1)"
+		arg = pq.Array(filter.IDs)
+	case filter.CategoryID != 0:
+		query += "p.category_id = $1"
+		arg = filter.CategoryID
+	default:
+		query += "DATE(p.price_updated_at) >= $1"
+		arg = filter.ChangedSince
+	}
+	if len(filter.IDs) == 0 {
+		// induk yang punya varian nggak dijual, jadi nggak perlu label
+		query += " AND NOT EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)"
+	}
+	query += " ORDER BY p.name, p.id"
+
+	rows, err := repo.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	products := make([]models.Product, 0)
 	for rows.Next() {
-		var p models.Product
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID)
+		p, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
-		products = append(products, p)
+		products = append(products, *p)
 	}
 
-	return products, nil
+	return products, rows.Err()
 }
 
 func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
-	return err
+	return withTx(repo.db, func(tx *sql.Tx) error {
+		query := "INSERT INTO products (name, sku, price, stock, allow_backorder, tax_rate, category_id, variant_options) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8) RETURNING id"
+		err := tx.QueryRow(query, product.Name, product.SKU, product.Price, product.Stock, product.AllowBackorder, product.TaxRate, product.CategoryID, variantOptionsJSON(product.VariantOptions)).Scan(&product.ID)
+		if err != nil {
+			return skuTaken(err)
+		}
+
+		return setBarcodes(tx, product)
+	})
+}
+
+// CreateVariant adds a variant to the parent product. The variant takes
+// the parent's category, and its tax rate unless it has its own; without a
+// name it is named after the parent and its option values.
+func (repo *ProductRepository) CreateVariant(parentID int, variant *models.Product) error {
+	return withTx(repo.db, func(tx *sql.Tx) error {
+		// parent dikunci supaya variant_options nggak berubah di tengah jalan
+		var parentName string
+		var parentTaxRate *float64
+		var grandparentID *int
+		var options []byte
+		err := tx.QueryRow(
+			"SELECT name, tax_rate, category_id, parent_id, variant_options FROM products WHERE id = $1 FOR UPDATE", parentID,
+		).Scan(&parentName, &parentTaxRate, &variant.CategoryID, &grandparentID, &options)
+		if err == sql.ErrNoRows {
+			return &models.NotFoundError{Resource: "Product"}
+		}
+		if err != nil {
+			return err
+		}
+		if grandparentID != nil {
+			return &models.ValidationError{Message: "a variant cannot have variants of its own"}
+		}
+
+		var variantOptions []models.VariantOption
+		if err := json.Unmarshal(options, &variantOptions); err != nil {
+			return err
+		}
+		if err := variant.Options.Check(variantOptions); err != nil {
+			return &models.ValidationError{
+				Message: "invalid variant",
+				Fields:  []models.FieldError{{Field: "options", Message: err.Error()}},
+			}
+		}
+
+		if variant.Name == "" {
+			variant.Name = parentName + " - " + variant.Options.Label(variantOptions)
+		}
+		if variant.TaxRate == nil {
+			variant.TaxRate = parentTaxRate
+		}
+		variant.ParentID = &parentID
+		variant.VariantOptions = nil
+
+		values, err := json.Marshal(variant.Options)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(
+			"INSERT INTO products (name, sku, price, stock, allow_backorder, tax_rate, category_id, parent_id, options) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+			variant.Name, variant.SKU, variant.Price, variant.Stock, variant.AllowBackorder, variant.TaxRate, variant.CategoryID, parentID, values,
+		).Scan(&variant.ID)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_products_parent_options" {
+			return &models.ValidationError{
+				Message: "invalid variant",
+				Fields:  []models.FieldError{{Field: "options", Message: "a variant with these options already exists"}},
+			}
+		}
+		if err != nil {
+			return skuTaken(err)
+		}
+
+		return setBarcodes(tx, variant)
+	})
 }
 
-// GetByID - ambil products by ID
+// GetVariants returns the variants of a product in the order they were
+// added.
+func (repo *ProductRepository) GetVariants(parentID int) ([]models.Product, error) {
+	rows, err := repo.db.Query(`
+	SELECT `+productColumns+`, c.name
+	FROM products p
+	JOIN categories c ON p.category_id = c.id
+	WHERE p.parent_id = $1
+	ORDER BY p.id`, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make([]models.Product, 0)
+	for rows.Next() {
+		var categoryName string
+		p, err := scanProduct(rows, &categoryName)
+		if err != nil {
+			return nil, err
+		}
+		p.CategoryName = categoryName
+		variants = append(variants, *p)
+	}
+
+	return variants, rows.Err()
+}
+
+// GetByID - ambil products by ID, sekalian variannya kalau ada
 func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
-	// query := "SELECT id, name, price, stock, category_id FROM products WHERE id = $1"
+	p, err := repo.getBy("p.id = $1", id)
+	if err != nil || !p.HasVariants {
+		return p, err
+	}
+
+	p.Variants, err = repo.GetVariants(id)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetByBarcode finds the product a scanned barcode belongs to. code must
+// already be normalised.
+func (repo *ProductRepository) GetByBarcode(code string) (*models.Product, error) {
+	return repo.getBy("p.id = (SELECT b.product_id FROM product_barcodes b WHERE b.barcode = $1)", code)
+}
+
+func (repo *ProductRepository) getBy(condition string, value any) (*models.Product, error) {
 	query := `
-	SELECT p.id, p.name, p.price, p.stock, p.category_id, c.name
+	SELECT ` + productColumns + `, c.name
 	FROM products p
 	JOIN categories c ON p.category_id = c.id
-	WHERE p.id = $1
-	`
+	WHERE ` + condition
 
-	var p models.Product
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+	var categoryName string
+	p, err := scanProduct(repo.db.QueryRow(query, value), &categoryName)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("Product is not found")
 	}
 	if err != nil {
 		return nil, err
 	}
+	p.CategoryName = categoryName
 
-	return &p, nil
+	return p, nil
 }
 
 func (repo *ProductRepository) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.CategoryID, product.ID)
+	return withTx(repo.db, func(tx *sql.Tx) error {
+		// price_updated_at cuma berubah kalau harganya benar-benar berubah
+		query := `UPDATE products SET name = $1, sku = NULLIF($2, ''), price = $3, stock = $4, allow_backorder = $5, tax_rate = $6, category_id = $7,
+			price_updated_at = CASE WHEN price <> $3 THEN NOW() ELSE price_updated_at END
+			WHERE id = $8`
+		result, err := tx.Exec(query, product.Name, product.SKU, product.Price, product.Stock, product.AllowBackorder, product.TaxRate, product.CategoryID, product.ID)
+		if err != nil {
+			return skuTaken(err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return errors.New("Product is not found")
+		}
+
+		if err := updateVariantOptions(tx, product); err != nil {
+			return err
+		}
+
+		// barcodes nggak dikirim berarti yang lama dibiarkan
+		if product.Barcodes == nil {
+			var barcodes pq.StringArray
+			err := tx.QueryRow(
+				"SELECT ARRAY(SELECT barcode FROM product_barcodes WHERE product_id = $1 ORDER BY barcode)", product.ID,
+			).Scan(&barcodes)
+			product.Barcodes = append(make([]string, 0, len(barcodes)), barcodes...)
+			return err
+		}
+
+		_, err = tx.Exec("DELETE FROM product_barcodes WHERE product_id = $1", product.ID)
+		if err != nil {
+			return err
+		}
+		return setBarcodes(tx, product)
+	})
+}
+
+// AssignBarcode gives a product without any barcode the next code from
+// the internal range. A product that already has a barcode is returned as
+// it is, with assigned false.
+func (repo *ProductRepository) AssignBarcode(id int) (product *models.Product, assigned bool, err error) {
+	err = withTx(repo.db, func(tx *sql.Tx) error {
+		assigned = false
+
+		var count int
+		err := tx.QueryRow(
+			"SELECT (SELECT COUNT(*) FROM product_barcodes WHERE product_id = p.id) FROM products p WHERE p.id = $1 FOR UPDATE", id,
+		).Scan(&count)
+		if err == sql.ErrNoRows {
+			return &models.NotFoundError{Resource: "Product"}
+		}
+		if err != nil || count > 0 {
+			return err
+		}
+
+		// kode yang sudah dipakai manual dilewati, ambil nomor berikutnya
+		for {
+			var seq int
+			err := tx.QueryRow(`
+				INSERT INTO barcode_counters (prefix, last_seq) VALUES ($1, 1)
+				ON CONFLICT (prefix) DO UPDATE SET last_seq = barcode_counters.last_seq + 1
+				RETURNING last_seq
+			`, repo.internal.Prefix).Scan(&seq)
+			if err != nil {
+				return err
+			}
+
+			code, err := repo.internal.Code(seq)
+			if err != nil {
+				return err
+			}
+
+			result, err := tx.Exec("INSERT INTO product_barcodes (barcode, product_id) VALUES ($1, $2) ON CONFLICT (barcode) DO NOTHING", code, id)
+			if err != nil {
+				return err
+			}
+			if n, err := result.RowsAffected(); err != nil || n == 1 {
+				assigned = true
+				return err
+			}
+		}
+	})
 	if err != nil {
+		return nil, false, err
+	}
+
+	product, err = repo.GetByID(id)
+	return product, assigned, err
+}
+
+// updateVariantOptions stores new variant options of a product, as long as
+// every existing variant still fits them. Leaving them out keeps the
+// current ones.
+func updateVariantOptions(tx *sql.Tx, product *models.Product) error {
+	if product.VariantOptions == nil {
+		var options []byte
+		err := tx.QueryRow("SELECT variant_options FROM products WHERE id = $1", product.ID).Scan(&options)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(options, &product.VariantOptions)
+	}
+
+	var parentID *int
+	if err := tx.QueryRow("SELECT parent_id FROM products WHERE id = $1", product.ID).Scan(&parentID); err != nil {
 		return err
 	}
+	if parentID != nil && len(product.VariantOptions) > 0 {
+		return errors.New("a variant cannot have variant options")
+	}
 
-	rows, err := result.RowsAffected()
+	rows, err := tx.Query("SELECT name, options FROM products WHERE parent_id = $1", product.ID)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	if rows == 0 {
-		return errors.New("Product is not found")
+	for rows.Next() {
+		var name string
+		var raw []byte
+		var values models.VariantValues
+		if err := rows.Scan(&name, &raw); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return err
+		}
+		if err := values.Check(product.VariantOptions); err != nil {
+			return fmt.Errorf("variant %s no longer fits the variant options: %w", name, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
+	_, err = tx.Exec("UPDATE products SET variant_options = $1 WHERE id = $2", variantOptionsJSON(product.VariantOptions), product.ID)
+	return err
+}
+
+func variantOptionsJSON(options []models.VariantOption) []byte {
+	if options == nil {
+		options = make([]models.VariantOption, 0)
+	}
+	data, _ := json.Marshal(options)
+	return data
+}
+
+// setBarcodes stores the barcodes of a product that has none yet.
+func setBarcodes(tx *sql.Tx, product *models.Product) error {
+	if product.Barcodes == nil {
+		product.Barcodes = make([]string, 0)
+	}
+
+	for _, code := range product.Barcodes {
+		_, err := tx.Exec("INSERT INTO product_barcodes (barcode, product_id) VALUES ($1, $2)", code, product.ID)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("barcode %s is already used by another product", code)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// skuTaken turns a unique violation on the SKU into a readable error.
+func skuTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "products_sku_key" {
+		return errors.New("sku is already used by another product")
+	}
+	return err
+}
+
+// productInUse turns the foreign key errors of deleting a product that is
+// still referenced into validation errors.
+func productInUse(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23503" {
+		return err
+	}
+	switch pqErr.Constraint {
+	case "products_parent_id_fkey":
+		return &models.ValidationError{Message: "product has variants; delete its variants first"}
+	case "transaction_details_product_id_fkey", "order_items_product_id_fkey":
+		return &models.ValidationError{Message: "product is used in sales or orders and cannot be deleted"}
+	}
+	return err
+}
+
+// Delete removes a product. A parent cannot be deleted while it still has
+// variants, nor a product that sales or orders still refer to.
 func (repo *ProductRepository) Delete(id int) error {
 	query := "DELETE FROM products WHERE id = $1"
 	result, err := repo.db.Exec(query, id)
 	if err != nil {
-		return err
+		return productInUse(err)
 	}
 	rows, err := result.RowsAffected()
 	if err != nil {
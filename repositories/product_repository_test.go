@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+func TestProductInUse(t *testing.T) {
+	other := errors.New("connection reset")
+
+	tests := []struct {
+		name           string
+		err            error
+		wantValidation bool
+	}{
+		{name: "variants", err: &pq.Error{Code: "23503", Constraint: "products_parent_id_fkey"}, wantValidation: true},
+		{name: "sold", err: &pq.Error{Code: "23503", Constraint: "transaction_details_product_id_fkey"}, wantValidation: true},
+		{name: "on an order", err: &pq.Error{Code: "23503", Constraint: "order_items_product_id_fkey"}, wantValidation: true},
+		{name: "other foreign key", err: &pq.Error{Code: "23503", Constraint: "something_else_fkey"}},
+		{name: "other database error", err: &pq.Error{Code: "40001"}},
+		{name: "not a database error", err: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := productInUse(tt.err)
+			var validationErr *models.ValidationError
+			if errors.As(got, &validationErr) != tt.wantValidation {
+				t.Errorf("productInUse() = %v, want a ValidationError: %v", got, tt.wantValidation)
+			}
+			if !tt.wantValidation && got != tt.err {
+				t.Errorf("productInUse() = %v, want the error unchanged", got)
+			}
+		})
+	}
+}
+
+func TestDeleteProductInUse(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db, nil)
+	orders := NewOrderRepository(db, nil)
+
+	name := fmt.Sprintf("delete test %d", time.Now().UnixNano())
+	var categoryID, parentID, variantID, orderedID int
+	err := db.QueryRow("INSERT INTO categories (name) VALUES ($1) RETURNING id", name).Scan(&categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []*int{&parentID, &orderedID} {
+		err = db.QueryRow(
+			"INSERT INTO products (name, price, stock, category_id) VALUES ($1, 1000, 10, $2) RETURNING id",
+			name, categoryID,
+		).Scan(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = db.QueryRow(
+		`INSERT INTO products (name, price, stock, category_id, parent_id, options) VALUES ($1, 1000, 10, $2, $3, '{"size": "S"}') RETURNING id`,
+		name, categoryID, parentID,
+	).Scan(&variantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = orders.Create(models.OrderRequest{Label: name, Items: []models.OrderItemRequest{{ProductID: orderedID, Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int{parentID, orderedID} {
+		var validationErr *models.ValidationError
+		if err := products.Delete(id); !errors.As(err, &validationErr) {
+			t.Errorf("Delete(%d) error = %v, want a ValidationError", id, err)
+		}
+	}
+
+	// variannya boleh dihapus, setelah itu parent-nya juga
+	if err := products.Delete(variantID); err != nil {
+		t.Fatalf("Delete(variant) error = %v", err)
+	}
+	if err := products.Delete(parentID); err != nil {
+		t.Errorf("Delete(parent) error = %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+type ReceivableRepository struct {
+	db           *sql.DB
+	requireShift bool
+}
+
+func NewReceivableRepository(db *sql.DB, requireShift bool) *ReceivableRepository {
+	return &ReceivableRepository{db: db, requireShift: requireShift}
+}
+
+// GetByCustomer returns a customer's unpaid receivables, oldest first.
+func (repo *ReceivableRepository) GetByCustomer(customerID int) (*models.CustomerReceivables, error) {
+	res := &models.CustomerReceivables{CustomerID: customerID, Receivables: make([]models.Receivable, 0)}
+	err := repo.db.QueryRow("SELECT credit_limit FROM customers WHERE id = $1", customerID).Scan(&res.CreditLimit)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Customer"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.customer_id, r.transaction_id, COALESCE(t.invoice_number, ''), r.amount, r.paid_amount, r.credited_amount,
+			CURRENT_DATE - DATE(r.created_at), r.created_at
+		FROM receivables r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.customer_id = $1 AND r.amount > r.paid_amount + r.credited_amount
+		ORDER BY r.created_at, r.id
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.Receivable
+		err := rows.Scan(&r.ID, &r.CustomerID, &r.TransactionID, &r.InvoiceNumber, &r.Amount, &r.PaidAmount, &r.CreditedAmount, &r.AgeDays, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		r.Outstanding = r.Amount - r.PaidAmount - r.CreditedAmount
+		res.Outstanding += r.Outstanding
+		res.Receivables = append(res.Receivables, r)
+	}
+
+	return res, rows.Err()
+}
+
+// Repay records a repayment and applies it to the customer's oldest
+// receivables first. A customer cannot pay more than they owe.
+func (repo *ReceivableRepository) Repay(customerID int, req models.RepaymentRequest) (*models.Repayment, error) {
+	var res *models.Repayment
+
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		shiftID, err := resolveShift(tx, req.ShiftID, req.Cashier, repo.requireShift)
+		if err != nil {
+			return err
+		}
+
+		// kunci pelanggan biar dua pembayaran barengan nggak dobel ngelunasin
+		if _, err := lockCustomer(tx, customerID); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(`
+			SELECT id, transaction_id, amount - paid_amount - credited_amount
+			FROM receivables
+			WHERE customer_id = $1 AND amount > paid_amount + credited_amount
+			ORDER BY created_at, id
+		`, customerID)
+		if err != nil {
+			return err
+		}
+
+		allocations := make([]models.RepaymentAllocation, 0)
+		left := req.Amount
+		outstanding := 0
+		for rows.Next() {
+			var a models.RepaymentAllocation
+			var open int
+			if err := rows.Scan(&a.ReceivableID, &a.TransactionID, &open); err != nil {
+				rows.Close()
+				return err
+			}
+			outstanding += open
+			if left > 0 {
+				a.Amount = min(left, open)
+				left -= a.Amount
+				allocations = append(allocations, a)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if req.Amount > outstanding {
+			return &models.ValidationError{
+				Message: "invalid repayment",
+				Fields: []models.FieldError{{
+					Field:   "amount",
+					Message: fmt.Sprintf("amount of %d exceeds the outstanding balance of %d", req.Amount, outstanding),
+				}},
+			}
+		}
+
+		res = &models.Repayment{
+			CustomerID:  customerID,
+			Amount:      req.Amount,
+			Method:      req.Method,
+			Reference:   req.Reference,
+			ShiftID:     shiftID,
+			Allocations: allocations,
+		}
+		err = tx.QueryRow(
+			"INSERT INTO repayments (customer_id, amount, method, reference, shift_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+			customerID, req.Amount, req.Method, req.Reference, shiftID,
+		).Scan(&res.ID, &res.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range allocations {
+			_, err = tx.Exec(
+				"INSERT INTO repayment_allocations (repayment_id, receivable_id, amount) VALUES ($1, $2, $3)",
+				res.ID, a.ReceivableID, a.Amount,
+			)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("UPDATE receivables SET paid_amount = paid_amount + $1 WHERE id = $2", a.Amount, a.ReceivableID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// settleCredit checks the "on_account" part of a checkout against the
+// customer's credit limit and returns its amount. The receivable itself is
+// written once the transaction exists.
+func settleCredit(tx *sql.Tx, req models.CheckoutRequest) (int, error) {
+	amount := 0
+	for _, payment := range req.Payments {
+		if payment.Method == models.PaymentMethodOnAccount {
+			amount += payment.Amount
+		}
+	}
+	if amount == 0 {
+		return 0, nil
+	}
+
+	if req.CustomerID == 0 {
+		return 0, &models.ValidationError{
+			Message: "invalid payments",
+			Fields:  []models.FieldError{{Field: "customer_id", Message: "a customer is required to pay on account"}},
+		}
+	}
+
+	// pelanggan sudah dikunci di settlePoints, jadi saldo piutangnya aman dibaca
+	var limit, outstanding int
+	err := tx.QueryRow(`
+		SELECT c.credit_limit, COALESCE((SELECT SUM(r.amount - r.paid_amount - r.credited_amount) FROM receivables r WHERE r.customer_id = c.id), 0)
+		FROM customers c
+		WHERE c.id = $1
+	`, req.CustomerID).Scan(&limit, &outstanding)
+	if err != nil {
+		return 0, err
+	}
+
+	if outstanding+amount > limit {
+		return 0, &models.ValidationError{
+			Message: "invalid payments",
+			Fields: []models.FieldError{{
+				Field:   "payments",
+				Message: fmt.Sprintf("on account amount of %d would exceed the credit limit of %d (outstanding %d)", amount, limit, outstanding),
+			}},
+		}
+	}
+
+	return amount, nil
+}
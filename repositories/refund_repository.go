@@ -0,0 +1,388 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+type RefundRepository struct {
+	db           *sql.DB
+	requireShift bool
+}
+
+func NewRefundRepository(db *sql.DB, requireShift bool) *RefundRepository {
+	return &RefundRepository{db: db, requireShift: requireShift}
+}
+
+// refundableLine is a transaction detail line together with how much of it
+// has already been refunded.
+type refundableLine struct {
+	detail         models.TransactionDetail
+	refundedAmount int
+	refundedTax    int
+}
+
+// Create refunds items from a transaction, puts the stock back and updates
+// the transaction status. A nil items slice refunds everything, which is
+// what a void does. The refund is booked on shiftID or, when that is 0, on
+// the open shift of cashier; it may have no shift when shifts are not
+// required.
+func (repo *RefundRepository) Create(transactionID int, refundType, reason string, items []models.RefundItem, shiftID int, cashier string) (*models.Refund, error) {
+	var (
+		res *models.Refund
+	)
+
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		shift, err := resolveShift(tx, shiftID, cashier, repo.requireShift)
+		if err != nil {
+			return err
+		}
+
+		res, err = createRefund(tx, transactionID, refundType, reason, items, shift)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func createRefund(tx *sql.Tx, transactionID int, refundType, reason string, items []models.RefundItem, shiftID *int) (*models.Refund, error) {
+	// kunci transaksinya biar dua refund barengan nggak bisa lebih dari yang dijual
+	var status string
+	var customerID *int
+	var totalAmount, roundingAmount, pointsEarned, pointsRedeemed int
+	err := tx.QueryRow(
+		"SELECT status, customer_id, total_amount, rounding_amount, points_earned, points_redeemed FROM transactions WHERE id = $1 FOR UPDATE",
+		transactionID,
+	).Scan(&status, &customerID, &totalAmount, &roundingAmount, &pointsEarned, &pointsRedeemed)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Transaction"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case models.TransactionStatusVoided:
+		return nil, &models.ValidationError{Message: "transaction is already voided"}
+	case models.TransactionStatusRefunded:
+		return nil, &models.ValidationError{Message: "transaction is already fully refunded"}
+	case models.TransactionStatusPartiallyRefunded:
+		if refundType == models.RefundTypeVoid {
+			return nil, &models.ValidationError{Message: "transaction has refunds and can no longer be voided; refund the remaining items instead"}
+		}
+	}
+
+	lines, order, err := getRefundableLines(tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if items == nil {
+		for _, id := range order {
+			line := lines[id]
+			if remaining := line.detail.Quantity - line.detail.RefundedQuantity; remaining > 0 {
+				items = append(items, models.RefundItem{TransactionDetailID: id, Quantity: remaining})
+			}
+		}
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	for i, item := range items {
+		line, ok := lines[item.TransactionDetailID]
+		if !ok {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].transaction_detail_id", i),
+				Message: fmt.Sprintf("detail %d does not belong to transaction %d", item.TransactionDetailID, transactionID),
+			})
+			continue
+		}
+
+		if remaining := line.detail.Quantity - line.detail.RefundedQuantity; item.Quantity > remaining {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: fmt.Sprintf("only %d of %s left to refund", remaining, line.detail.ProductName),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid refund items", Fields: fieldErrors}
+	}
+	if len(items) == 0 {
+		return nil, &models.ValidationError{Message: "nothing left to refund"}
+	}
+
+	refund := &models.Refund{
+		TransactionID: transactionID,
+		Type:          refundType,
+		Reason:        reason,
+		Details:       make([]models.RefundDetail, 0, len(items)),
+	}
+
+	refundedBefore := 0
+	for _, line := range lines {
+		refundedBefore += line.refundedAmount
+	}
+
+	restock := make(map[int]int)
+	for _, item := range items {
+		line := lines[item.TransactionDetailID]
+
+		// nilai refund dihitung kumulatif supaya total semua refund
+		// per baris pas sama dengan yang dibayar, nggak ada selisih pembulatan
+		refundedQty := line.detail.RefundedQuantity + item.Quantity
+		refundedAmount := line.detail.TotalAmount * refundedQty / line.detail.Quantity
+		refundedTax := line.detail.TaxAmount * refundedQty / line.detail.Quantity
+		amount := refundedAmount - line.refundedAmount
+		tax := refundedTax - line.refundedTax
+
+		line.detail.RefundedQuantity = refundedQty
+		line.refundedAmount = refundedAmount
+		line.refundedTax = refundedTax
+		refund.TotalAmount += amount
+		refund.TaxAmount += tax
+		restock[line.detail.ProductID] += item.Quantity
+
+		refund.Details = append(refund.Details, models.RefundDetail{
+			TransactionDetailID: line.detail.ID,
+			ProductID:           line.detail.ProductID,
+			ProductName:         line.detail.ProductName,
+			Quantity:            item.Quantity,
+			Amount:              amount,
+			TaxAmount:           tax,
+		})
+	}
+
+	status = models.TransactionStatusRefunded
+	if refundType == models.RefundTypeVoid {
+		status = models.TransactionStatusVoided
+	}
+	for _, line := range lines {
+		if line.detail.RefundedQuantity < line.detail.Quantity {
+			status = models.TransactionStatusPartiallyRefunded
+			break
+		}
+	}
+
+	// pembulatan tunai ikut dibalikin sebanding, dihitung kumulatif juga;
+	// refund terakhir dapat sisanya biar total pembulatannya pas
+	if roundingAmount != 0 && totalAmount > 0 {
+		var roundedBefore int
+		err = tx.QueryRow(
+			"SELECT COALESCE(SUM(rounding_amount), 0) FROM refunds WHERE transaction_id = $1",
+			transactionID,
+		).Scan(&roundedBefore)
+		if err != nil {
+			return nil, err
+		}
+		rounded := roundingAmount * (refundedBefore + refund.TotalAmount) / totalAmount
+		if status != models.TransactionStatusPartiallyRefunded {
+			rounded = roundingAmount
+		}
+		refund.RoundingAmount = rounded - roundedBefore
+	}
+
+	// kalau transaksinya kasbon yang belum lunas, refund motong utangnya dulu
+	var receivableID, owed int
+	err = tx.QueryRow(
+		"SELECT id, amount - paid_amount - credited_amount FROM receivables WHERE transaction_id = $1 FOR UPDATE",
+		transactionID,
+	).Scan(&receivableID, &owed)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	refund.CreditAmount = min(refund.TotalAmount, max(owed, 0))
+	if refund.CreditAmount > 0 {
+		_, err = tx.Exec("UPDATE receivables SET credited_amount = credited_amount + $1 WHERE id = $2", refund.CreditAmount, receivableID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// sisanya dibayar tunai sebanyak uang tunai yang masuk dari transaksi ini
+	// dan belum dikembalikan, lalu lewat metode non-tunai
+	var cashLeft int
+	err = tx.QueryRow(`
+		SELECT COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.transaction_id = t.id AND p.method = 'cash'), 0)
+			- t.change_amount
+			- COALESCE((SELECT SUM(r.cash_amount) FROM refunds r WHERE r.transaction_id = t.id), 0)
+		FROM transactions t
+		WHERE t.id = $1
+	`, transactionID).Scan(&cashLeft)
+	if err != nil {
+		return nil, err
+	}
+	refund.CashAmount = min(refund.TotalAmount+refund.RoundingAmount-refund.CreditAmount, max(cashLeft, 0))
+	refund.ShiftID = shiftID
+
+	err = tx.QueryRow(
+		"INSERT INTO refunds (transaction_id, type, total_amount, tax_amount, credit_amount, cash_amount, rounding_amount, shift_id, reason) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at",
+		transactionID, refundType, refund.TotalAmount, refund.TaxAmount, refund.CreditAmount, refund.CashAmount, refund.RoundingAmount, shiftID, reason,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerID != nil {
+		err = reversePoints(tx, refund, *customerID, totalAmount, pointsEarned, pointsRedeemed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range refund.Details {
+		d := &refund.Details[i]
+		d.RefundID = refund.ID
+		err = tx.QueryRow(
+			"INSERT INTO refund_details (refund_id, transaction_detail_id, product_id, quantity, amount, tax_amount) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			refund.ID, d.TransactionDetailID, d.ProductID, d.Quantity, d.Amount, d.TaxAmount,
+		).Scan(&d.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// balikin stok, urut id produk sama seperti checkout biar nggak deadlock
+	productIDs := make([]int, 0, len(restock))
+	for id := range restock {
+		productIDs = append(productIDs, id)
+	}
+	slices.Sort(productIDs)
+	for _, id := range productIDs {
+		_, err = tx.Exec("UPDATE products SET stock = stock + $1 WHERE id = $2", restock[id], id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE transactions SET status = $1 WHERE id = $2", status, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// reversePoints takes back the points a customer earned on the refunded
+// part of a transaction and, on a void, gives back the points they spent.
+// Like the refund amounts, the points taken back are worked out
+// cumulatively so repeated partial refunds never take back too many.
+func reversePoints(tx *sql.Tx, refund *models.Refund, customerID, totalAmount, pointsEarned, pointsRedeemed int) error {
+	var refundedBefore, reversedBefore int
+	err := tx.QueryRow(
+		"SELECT COALESCE(SUM(total_amount), 0), COALESCE(SUM(points_reversed), 0) FROM refunds WHERE transaction_id = $1 AND id <> $2",
+		refund.TransactionID, refund.ID,
+	).Scan(&refundedBefore, &reversedBefore)
+	if err != nil {
+		return err
+	}
+
+	if totalAmount > 0 {
+		refund.PointsReversed = pointsEarned*(refundedBefore+refund.TotalAmount)/totalAmount - reversedBefore
+	}
+	if refund.Type == models.RefundTypeVoid {
+		refund.PointsReversed = pointsEarned - reversedBefore
+		refund.PointsRestored = pointsRedeemed
+	}
+
+	if _, err := lockCustomer(tx, customerID); err != nil {
+		return err
+	}
+	err = addPoints(tx, customerID, models.PointsReverse, -refund.PointsReversed, &refund.TransactionID, &refund.ID)
+	if err != nil {
+		return err
+	}
+	err = addPoints(tx, customerID, models.PointsRestore, refund.PointsRestored, &refund.TransactionID, &refund.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE refunds SET points_reversed = $1, points_restored = $2 WHERE id = $3",
+		refund.PointsReversed, refund.PointsRestored, refund.ID,
+	)
+	return err
+}
+
+func getRefundableLines(tx *sql.Tx, transactionID int) (map[int]*refundableLine, []int, error) {
+	rows, err := tx.Query(`
+		SELECT td.id, td.product_id, td.product_name, td.quantity, td.tax_amount, td.total_amount,
+			COALESCE(SUM(rd.quantity), 0), COALESCE(SUM(rd.amount), 0), COALESCE(SUM(rd.tax_amount), 0)
+		FROM transaction_details td
+		LEFT JOIN refund_details rd ON rd.transaction_detail_id = td.id
+		WHERE td.transaction_id = $1
+		GROUP BY td.id
+		ORDER BY td.id
+	`, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	lines := make(map[int]*refundableLine)
+	order := make([]int, 0)
+	for rows.Next() {
+		line := &refundableLine{}
+		d := &line.detail
+		err := rows.Scan(&d.ID, &d.ProductID, &d.ProductName, &d.Quantity, &d.TaxAmount, &d.TotalAmount, &d.RefundedQuantity, &line.refundedAmount, &line.refundedTax)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.TransactionID = transactionID
+		lines[d.ID] = line
+		order = append(order, d.ID)
+	}
+
+	return lines, order, rows.Err()
+}
+
+// GetByTransactionIDs returns the refunds of the given transactions, keyed by transaction ID.
+func (repo *RefundRepository) GetByTransactionIDs(transactionIDs []int) (map[int][]models.Refund, error) {
+	refunds := make(map[int][]models.Refund, len(transactionIDs))
+	if len(transactionIDs) == 0 {
+		return refunds, nil
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.transaction_id, r.type, r.total_amount, r.tax_amount, r.credit_amount, r.cash_amount, r.rounding_amount, r.shift_id, r.points_reversed, r.points_restored, r.reason, r.created_at,
+			rd.id, rd.transaction_detail_id, rd.product_id, td.product_name, rd.quantity, rd.amount, rd.tax_amount
+		FROM refunds r
+		JOIN refund_details rd ON rd.refund_id = r.id
+		JOIN transaction_details td ON td.id = rd.transaction_detail_id
+		WHERE r.transaction_id = ANY($1)
+		ORDER BY r.id, rd.id
+	`, pq.Array(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.Refund
+		var d models.RefundDetail
+		err := rows.Scan(
+			&r.ID, &r.TransactionID, &r.Type, &r.TotalAmount, &r.TaxAmount, &r.CreditAmount, &r.CashAmount, &r.RoundingAmount, &r.ShiftID, &r.PointsReversed, &r.PointsRestored, &r.Reason, &r.CreatedAt,
+			&d.ID, &d.TransactionDetailID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Amount, &d.TaxAmount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.RefundID = r.ID
+
+		list := refunds[r.TransactionID]
+		if n := len(list); n == 0 || list[n-1].ID != r.ID {
+			r.Details = make([]models.RefundDetail, 0)
+			list = append(list, r)
+		}
+		list[len(list)-1].Details = append(list[len(list)-1].Details, d)
+		refunds[r.TransactionID] = list
+	}
+
+	return refunds, rows.Err()
+}
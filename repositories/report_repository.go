@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"kasir-api/models"
 )
 
@@ -14,32 +15,82 @@ func NewReportRepository(db *sql.DB) *ReportRepository {
 }
 
 func (r *ReportRepository) GetTodayReport() (*models.TodayReport, error) {
+	return r.getReport("DATE(%s) = CURRENT_DATE")
+}
+
+func (r *ReportRepository) GetReportByDateRange(startDate, endDate string) (*models.TodayReport, error) {
+	return r.getReport("DATE(%s) BETWEEN $1 AND $2", startDate, endDate)
+}
+
+// getReport builds a report for the period described by dateFilter, a
+// condition with a single %s placeholder for the timestamp column.
+//
+// Voided transactions are left out entirely, as if they never happened.
+// Partial refunds are subtracted on the day they were made.
+func (r *ReportRepository) getReport(dateFilter string, args ...any) (*models.TodayReport, error) {
 	report := &models.TodayReport{}
+	salesFilter := fmt.Sprintf(dateFilter, "t.created_at")
+	refundsFilter := fmt.Sprintf(dateFilter, "r.created_at")
 
-	// total revenue + total transactions today
+	// sales before and after discounts, tax, cash rounding + total transactions.
+	// pembulatan dilaporkan terpisah, nggak masuk revenue
+	var sales, salesTax int
 	err := r.db.QueryRow(`
 		SELECT 
-			COALESCE(SUM(total_amount), 0),
+			COALESCE(SUM(t.gross_amount), 0),
+			COALESCE(SUM(t.discount_amount), 0),
+			COALESCE(SUM(t.subtotal), 0),
+			COALESCE(SUM(t.tax_amount), 0),
+			COALESCE(SUM(t.total_amount), 0),
+			COALESCE(SUM(t.rounding_amount), 0),
 			COUNT(*)
-		FROM transactions
-		WHERE DATE(created_at) = CURRENT_DATE
-	`).Scan(&report.TotalRevenue, &report.TotalTransactions)
+		FROM transactions t
+		WHERE t.status <> 'voided' AND `+salesFilter,
+		args...,
+	).Scan(&report.GrossSales, &report.TotalDiscounts, &report.NetSales, &salesTax, &sales, &report.TotalRounding, &report.TotalTransactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// pembulatan yang ikut dibalikin waktu refund dikurangi juga
+	var refundedTax, refundedRounding int
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(r.total_amount), 0), COALESCE(SUM(r.tax_amount), 0), COALESCE(SUM(r.rounding_amount), 0)
+		FROM refunds r
+		WHERE r.type = 'refund' AND `+refundsFilter,
+		args...,
+	).Scan(&report.TotalRefunds, &refundedTax, &refundedRounding)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// best selling product today
+	report.TotalTax = salesTax - refundedTax
+	report.TotalRounding -= refundedRounding
+	report.TotalRevenue = sales - report.TotalRefunds
+
+	// best selling product, net of refunded quantity. nama diambil dari
+	// snapshot baris transaksi terakhir, bukan dari tabel products
 	err = r.db.QueryRow(`
-		SELECT p.name, COALESCE(SUM(td.quantity), 0) AS qty
-		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
-		JOIN transactions t ON t.id = td.transaction_id
-		WHERE DATE(t.created_at) = CURRENT_DATE
-		GROUP BY p.name
+		SELECT (ARRAY_AGG(sold.name ORDER BY sold.detail_id DESC))[1], COALESCE(SUM(sold.qty), 0) AS qty
+		FROM (
+			SELECT td.id AS detail_id, td.product_id, td.product_name AS name, td.quantity AS qty
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE t.status <> 'voided' AND `+salesFilter+`
+			UNION ALL
+			SELECT td.id, rd.product_id, td.product_name, -rd.quantity
+			FROM refund_details rd
+			JOIN refunds r ON r.id = rd.refund_id
+			JOIN transaction_details td ON td.id = rd.transaction_detail_id
+			WHERE r.type = 'refund' AND `+refundsFilter+`
+		) sold
+		GROUP BY sold.product_id
+		HAVING SUM(sold.qty) > 0
 		ORDER BY qty DESC
 		LIMIT 1
-	`).Scan(&report.BestProduct.Name, &report.BestProduct.QtySold)
+	`, args...).Scan(&report.BestProduct.Name, &report.BestProduct.QtySold)
 
 	if err == sql.ErrNoRows {
 		report.BestProduct = models.BestSellingProduct{}
@@ -53,44 +104,92 @@ func (r *ReportRepository) GetTodayReport() (*models.TodayReport, error) {
 	return report, nil
 }
 
-func (r *ReportRepository) GetReportByDateRange(startDate, endDate string) (*models.TodayReport, error) {
-	report := &models.TodayReport{}
-
-	err := r.db.QueryRow(`
-		SELECT 
-			COALESCE(SUM(total_amount), 0),
-			COUNT(*)
-		FROM transactions
-		WHERE DATE(created_at) BETWEEN $1 AND $2
-	`, startDate, endDate).Scan(
-		&report.TotalRevenue,
-		&report.TotalTransactions,
-	)
+// GetProductSales returns what each product sold between the two dates,
+// net of refunds, best seller first. Grouped by parent, variants are added
+// up under their parent product; products without variants stay as they
+// are. Sales are grouped by the parent they had at checkout.
+func (r *ReportRepository) GetProductSales(startDate, endDate, groupBy string) (*models.ProductSalesReport, error) {
+	key, name := "td.product_id", "td.product_name"
+	if groupBy == models.GroupByParent {
+		key = "COALESCE(td.parent_id, td.product_id)"
+		name = "COALESCE((SELECT p.name FROM products p WHERE p.id = td.parent_id), td.product_name)"
+	}
 
+	rows, err := r.db.Query(`
+		SELECT sold.key, (ARRAY_AGG(sold.name ORDER BY sold.detail_id DESC))[1], SUM(sold.qty), SUM(sold.revenue)
+		FROM (
+			SELECT td.id AS detail_id, `+key+` AS key, `+name+` AS name, td.quantity AS qty, td.total_amount AS revenue
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE t.status <> 'voided' AND DATE(t.created_at) BETWEEN $1 AND $2
+			UNION ALL
+			SELECT td.id, `+key+`, `+name+`, -rd.quantity, -rd.amount
+			FROM refund_details rd
+			JOIN refunds r ON r.id = rd.refund_id
+			JOIN transaction_details td ON td.id = rd.transaction_detail_id
+			WHERE r.type = 'refund' AND DATE(r.created_at) BETWEEN $1 AND $2
+		) sold
+		GROUP BY sold.key
+		ORDER BY SUM(sold.qty) DESC, sold.key
+	`, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	err = r.db.QueryRow(`
-		SELECT p.name, COALESCE(SUM(td.quantity), 0) AS qty
-		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
-		JOIN transactions t ON t.id = td.transaction_id
-		WHERE DATE(t.created_at) BETWEEN $1 AND $2
-		GROUP BY p.name
-		ORDER BY qty DESC
-		LIMIT 1
-	`, startDate, endDate).Scan(
-		&report.BestProduct.Name,
-		&report.BestProduct.QtySold,
-	)
-
-	if err == sql.ErrNoRows {
-		return report, nil
+	report := &models.ProductSalesReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		GroupBy:   groupBy,
+		Products:  make([]models.ProductSales, 0),
+	}
+	for rows.Next() {
+		var p models.ProductSales
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.QtySold, &p.Revenue); err != nil {
+			return nil, err
+		}
+		report.Products = append(report.Products, p)
 	}
+
+	return report, rows.Err()
+}
+
+// GetReceivablesAging returns the debt still owed by each customer, split
+// by the age of the sale it comes from, largest balance first.
+func (r *ReportRepository) GetReceivablesAging() (*models.AgingReport, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.name, c.phone, c.credit_limit,
+			COALESCE(SUM(o.amount) FILTER (WHERE o.age <= 30), 0),
+			COALESCE(SUM(o.amount) FILTER (WHERE o.age BETWEEN 31 AND 60), 0),
+			COALESCE(SUM(o.amount) FILTER (WHERE o.age > 60), 0),
+			SUM(o.amount)
+		FROM (
+			SELECT customer_id, amount - paid_amount - credited_amount AS amount, CURRENT_DATE - DATE(created_at) AS age
+			FROM receivables
+			WHERE amount > paid_amount + credited_amount
+		) o
+		JOIN customers c ON c.id = o.customer_id
+		GROUP BY c.id
+		ORDER BY SUM(o.amount) DESC, c.name
+	`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	report := &models.AgingReport{Customers: make([]models.CustomerAging, 0)}
+	for rows.Next() {
+		var c models.CustomerAging
+		err := rows.Scan(&c.CustomerID, &c.Name, &c.Phone, &c.CreditLimit, &c.Days0To30, &c.Days31To60, &c.Over60, &c.Total)
+		if err != nil {
+			return nil, err
+		}
+		report.Totals.Days0To30 += c.Days0To30
+		report.Totals.Days31To60 += c.Days31To60
+		report.Totals.Over60 += c.Over60
+		report.Totals.Total += c.Total
+		report.Customers = append(report.Customers, c)
+	}
 
-	return report, nil
+	return report, rows.Err()
 }
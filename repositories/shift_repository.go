@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) *ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+// shiftQuery selects a shift together with the cash it has taken in and
+// paid out. Cash sales are cash payments minus the change given back.
+const shiftQuery = `
+	SELECT s.id, s.cashier, s.status, s.opening_float, s.expected_cash, s.counted_cash, s.note, s.opened_at, s.closed_at,
+		COALESCE((SELECT SUM(p.amount) FROM payments p JOIN transactions t ON t.id = p.transaction_id WHERE t.shift_id = s.id AND p.method = 'cash'), 0)
+			- COALESCE((SELECT SUM(t.change_amount) FROM transactions t WHERE t.shift_id = s.id), 0),
+		COALESCE((SELECT SUM(r.cash_amount) FROM refunds r WHERE r.shift_id = s.id), 0),
+		COALESCE((SELECT SUM(rp.amount) FROM repayments rp WHERE rp.shift_id = s.id AND rp.method = 'cash'), 0),
+		COALESCE((SELECT SUM(m.amount) FROM cash_movements m WHERE m.shift_id = s.id AND m.type = 'in'), 0),
+		COALESCE((SELECT SUM(m.amount) FROM cash_movements m WHERE m.shift_id = s.id AND m.type = 'out'), 0),
+		(SELECT COUNT(*) FROM transactions t WHERE t.shift_id = s.id)
+	FROM shifts s`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanShift(row rowScanner) (*models.Shift, error) {
+	var s models.Shift
+	var expected sql.NullInt64
+	err := row.Scan(
+		&s.ID, &s.Cashier, &s.Status, &s.OpeningFloat, &expected, &s.CountedCash, &s.Note, &s.OpenedAt, &s.ClosedAt,
+		&s.CashSales, &s.CashRefunds, &s.CashRepayments, &s.CashIn, &s.CashOut, &s.TotalTransactions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// shift yang sudah ditutup pakai angka yang dicatat waktu tutup
+	s.ExpectedCash = s.OpeningFloat + s.CashSales - s.CashRefunds + s.CashRepayments + s.CashIn - s.CashOut
+	if expected.Valid {
+		s.ExpectedCash = int(expected.Int64)
+	}
+	if s.CountedCash != nil {
+		variance := *s.CountedCash - s.ExpectedCash
+		s.Variance = &variance
+	}
+
+	return &s, nil
+}
+
+// Open starts a shift. A cashier can only have one open shift at a time.
+func (repo *ShiftRepository) Open(req models.OpenShiftRequest) (*models.Shift, error) {
+	var id int
+	err := repo.db.QueryRow(
+		"INSERT INTO shifts (cashier, opening_float) VALUES ($1, $2) RETURNING id",
+		req.Cashier, req.OpeningFloat,
+	).Scan(&id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, &models.ValidationError{
+			Message: "invalid shift",
+			Fields:  []models.FieldError{{Field: "cashier", Message: "cashier already has an open shift"}},
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// GetAll returns the shifts with the given status, newest first.
+func (repo *ShiftRepository) GetAll(status string) ([]models.Shift, error) {
+	rows, err := repo.db.Query(shiftQuery+" WHERE s.status = $1 ORDER BY s.opened_at DESC, s.id DESC", status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := make([]models.Shift, 0)
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, *s)
+	}
+
+	return shifts, rows.Err()
+}
+
+// GetByID returns a shift with its cash movements.
+func (repo *ShiftRepository) GetByID(id int) (*models.Shift, error) {
+	s, err := scanShift(repo.db.QueryRow(shiftQuery+" WHERE s.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Shift"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(
+		"SELECT id, shift_id, type, amount, reason, created_at FROM cash_movements WHERE shift_id = $1 ORDER BY id",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s.Movements = make([]models.CashMovement, 0)
+	for rows.Next() {
+		var m models.CashMovement
+		err := rows.Scan(&m.ID, &m.ShiftID, &m.Type, &m.Amount, &m.Reason, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		s.Movements = append(s.Movements, m)
+	}
+
+	return s, rows.Err()
+}
+
+// AddCashMovement records cash put into or taken out of an open shift's drawer.
+func (repo *ShiftRepository) AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	m := &models.CashMovement{ShiftID: shiftID, Type: req.Type, Amount: req.Amount, Reason: req.Reason}
+
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		if err := lockOpenShift(tx, shiftID, "FOR SHARE"); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(
+			"INSERT INTO cash_movements (shift_id, type, amount, reason) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+			shiftID, req.Type, req.Amount, req.Reason,
+		).Scan(&m.ID, &m.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Close ends a shift with the cash counted in the drawer. The expected
+// amount is worked out and stored at the same time, so the variance of a
+// closed shift only changes when an offline sale made during the shift is
+// synced later.
+func (repo *ShiftRepository) Close(id int, req models.CloseShiftRequest) (*models.Shift, error) {
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		// FOR UPDATE nunggu checkout yang lagi jalan di shift ini selesai dulu
+		if err := lockOpenShift(tx, id, "FOR UPDATE"); err != nil {
+			return err
+		}
+
+		s, err := scanShift(tx.QueryRow(shiftQuery+" WHERE s.id = $1", id))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE shifts SET status = $1, expected_cash = $2, counted_cash = $3, note = $4, closed_at = NOW() WHERE id = $5",
+			models.ShiftStatusClosed, s.ExpectedCash, *req.CountedCash, req.Note, id,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+// lockOpenShift locks a shift row with the given lock clause and checks that
+// it is still open.
+func lockOpenShift(tx *sql.Tx, id int, lock string) error {
+	var status string
+	err := tx.QueryRow("SELECT status FROM shifts WHERE id = $1 "+lock, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return &models.NotFoundError{Resource: "Shift"}
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != models.ShiftStatusOpen {
+		return &models.ValidationError{Message: "shift is already closed"}
+	}
+	return nil
+}
+
+// resolveShift finds the shift a checkout or refund belongs to: shiftID
+// when given, otherwise the open shift of cashier, if any. The shift is
+// locked FOR SHARE so it cannot be closed halfway through. When required
+// is set and no shift is found, the request is rejected.
+func resolveShift(tx *sql.Tx, shiftID int, cashier string, required bool) (*int, error) {
+	if shiftID != 0 {
+		err := lockOpenShift(tx, shiftID, "FOR SHARE")
+		var notFoundErr *models.NotFoundError
+		var validationErr *models.ValidationError
+		if errors.As(err, &notFoundErr) || errors.As(err, &validationErr) {
+			return nil, &models.ValidationError{
+				Message: "invalid shift",
+				Fields:  []models.FieldError{{Field: "shift_id", Message: err.Error()}},
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &shiftID, nil
+	}
+
+	if cashier != "" {
+		var id int
+		err := tx.QueryRow("SELECT id FROM shifts WHERE cashier = $1 AND status = 'open' FOR SHARE", cashier).Scan(&id)
+		if err == nil {
+			return &id, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	if required {
+		return nil, &models.ValidationError{
+			Message: "invalid shift",
+			Fields:  []models.FieldError{{Field: "shift_id", Message: "an open shift is required"}},
+		}
+	}
+	return nil, nil
+}
+
+// recordedShift checks the shift a synced sale was rung up on. The till
+// was offline, so the shift may have been closed since; it is kept as long
+// as it exists, and locked FOR SHARE like in resolveShift. A sale without a
+// shift is stored without one rather than being moved onto whatever shift
+// the cashier has open now.
+func recordedShift(tx *sql.Tx, shiftID int) (*int, error) {
+	if shiftID == 0 {
+		return nil, nil
+	}
+
+	var id int
+	err := tx.QueryRow("SELECT id FROM shifts WHERE id = $1 FOR SHARE", shiftID).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == sql.ErrNoRows {
+		return nil, &models.ValidationError{
+			Message: "invalid shift",
+			Fields:  []models.FieldError{{Field: "shift_id", Message: "Shift is not found"}},
+		}
+	}
+	return &shiftID, nil
+}
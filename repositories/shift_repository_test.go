@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+func TestSyncOntoClosedShift(t *testing.T) {
+	db := testDB(t)
+	transactions := testTransactionRepository(t, db)
+	shifts := NewShiftRepository(db)
+
+	productID := testProduct(t, db, 15000, 10)
+	shift, err := shifts.Open(models.OpenShiftRequest{Cashier: fmt.Sprintf("%s %d", t.Name(), time.Now().UnixNano()), OpeningFloat: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// laci dihitung waktu tutup, termasuk uang penjualan yang belum tersinkron
+	counted := 115000
+	closed, err := shifts.Close(shift.ID, models.CloseShiftRequest{CountedCash: &counted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *closed.Variance != 15000 {
+		t.Fatalf("variance before sync = %d, want 15000", *closed.Variance)
+	}
+
+	sale := models.SyncTransaction{
+		ClientID:  fmt.Sprintf("00000000-0000-4000-8000-%012d", time.Now().UnixNano()%1e12),
+		CreatedAt: closed.OpenedAt,
+		CheckoutRequest: models.CheckoutRequest{
+			ShiftID:  shift.ID,
+			Items:    []models.CheckoutItem{{ProductID: productID, Quantity: 1}},
+			Payments: []models.CheckoutPayment{{Method: models.PaymentMethodCash, Amount: 20000}},
+		},
+	}
+	res, _, err := transactions.Sync(sale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ShiftID == nil || *res.ShiftID != shift.ID {
+		t.Fatalf("sale shift = %v, want %d", res.ShiftID, shift.ID)
+	}
+
+	got, err := shifts.GetByID(shift.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CashSales != 15000 {
+		t.Errorf("CashSales = %d, want 15000", got.CashSales)
+	}
+	want := got.OpeningFloat + got.CashSales - got.CashRefunds + got.CashRepayments + got.CashIn - got.CashOut
+	if got.ExpectedCash != want {
+		t.Errorf("ExpectedCash = %d, want %d", got.ExpectedCash, want)
+	}
+	if got.Variance == nil || *got.Variance != 0 {
+		t.Errorf("Variance = %v, want 0", got.Variance)
+	}
+}
@@ -2,120 +2,805 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"kasir-api/invoice"
+	"kasir-api/loyalty"
 	"kasir-api/models"
+	"kasir-api/pricing"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type TransactionRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	calculator   *pricing.Calculator
+	numberer     *invoice.Numberer
+	loyalty      *loyalty.Program
+	requireShift bool
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB, calculator *pricing.Calculator, numberer *invoice.Numberer, loyalty *loyalty.Program, requireShift bool) *TransactionRepository {
+	return &TransactionRepository{db: db, calculator: calculator, numberer: numberer, loyalty: loyalty, requireShift: requireShift}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
+// CreateTransaction runs a checkout. When idempotencyKey is not nil, the
+// result is stored under that key in the same database transaction.
+func (repo *TransactionRepository) CreateTransaction(req models.CheckoutRequest, idempotencyKey *models.IdempotencyKey) (*models.Transaction, error) {
 	var (
 		res *models.Transaction
 	)
 
-	tx, err := repo.db.Begin()
+	err := withTx(repo.db, func(tx *sql.Tx) error {
+		var err error
+		res, err = repo.createTransaction(tx, req, nil)
+		if err != nil {
+			return err
+		}
+
+		if idempotencyKey != nil {
+			return saveIdempotencyKey(tx, idempotencyKey, res)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
 
-	// inisialisasi subtotal -> jumlah total transaksi keseluruhan
-	totalAmount := 0
-	// inisialisasi modeling transactionDetails -> nanti kita insert ke db
-	details := make([]models.TransactionDetail, 0)
-	// loop setiap item
-	for _, item := range items {
-		var productName string
-		var productID, price, stock int
+	return res, nil
+}
 
-		// get product dapet pricing
-		err := tx.QueryRow("SELECT id, name, price, stock FROM products WHERE id=$1", item.ProductID).Scan(&productID, &productName, &price, &stock)
+// Sync records a sale uploaded by a till that was offline, keeping the time
+// it was made. When a sale with the same client ID is already recorded,
+// that transaction is returned with duplicate set instead.
+func (repo *TransactionRepository) Sync(sale models.SyncTransaction) (res *models.Transaction, duplicate bool, err error) {
+	var existingID int
+	err = withTx(repo.db, func(tx *sql.Tx) error {
+		err := tx.QueryRow("SELECT id FROM transactions WHERE client_id = $1", sale.ClientID).Scan(&existingID)
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		res, err = repo.createTransaction(tx, sale.CheckoutRequest, &saleOrigin{clientID: sale.ClientID, soldAt: sale.CreatedAt})
+		return err
+	})
+
+	// dua upload barengan buat sale yang sama, yang kalah nabrak unique index
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "transactions_client_id_key" {
+		err = repo.db.QueryRow("SELECT id FROM transactions WHERE client_id = $1", sale.ClientID).Scan(&existingID)
+	}
+	if err != nil {
+		return nil, false, err
+	}
 
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+	if existingID != 0 {
+		res, err = repo.GetByID(existingID)
+		return res, true, err
+	}
+	return res, false, nil
+}
+
+// Quote prices req the way CreateTransaction would, but without locking
+// or writing anything. Stock and payment problems are reported as warnings
+// rather than errors so the basket can still be shown; unknown products and
+// invalid discounts fail just like a checkout.
+func (repo *TransactionRepository) Quote(req models.CheckoutRequest) (*models.CheckoutQuote, error) {
+	ids := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		ids = append(ids, item.ProductID)
+	}
+
+	products, err := loadProducts(repo.db, ids, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkItems(req.Items, products); err != nil {
+		return nil, err
+	}
+
+	res, err := repo.calculator.Price(req, products)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]models.QuoteWarning, 0)
+
+	// warning stok ditaruh di baris pertama yang pakai produknya
+	requested, _, shortages := checkStock(req.Items, products)
+	short := make(map[int]models.StockShortage, len(shortages))
+	for _, shortage := range shortages {
+		short[shortage.ProductID] = shortage
+	}
+	warned := make(map[int]bool)
+	for i, item := range req.Items {
+		p := products[item.ProductID]
+		if warned[p.ID] {
+			continue
 		}
 
+		field := fmt.Sprintf("items[%d]", i)
+		if shortage, ok := short[p.ID]; ok {
+			warnings = append(warnings, models.QuoteWarning{
+				Code:      models.QuoteWarningInsufficientStock,
+				Field:     field,
+				ProductID: p.ID,
+				Message:   fmt.Sprintf("%s: %d requested, only %d in stock", p.Name, shortage.Requested, shortage.Available),
+			})
+			warned[p.ID] = true
+		} else if p.AllowBackorder && requested[p.ID] > p.Stock {
+			warnings = append(warnings, models.QuoteWarning{
+				Code:      models.QuoteWarningBackorder,
+				Field:     field,
+				ProductID: p.ID,
+				Message:   fmt.Sprintf("%s: %d of %d will be backordered", p.Name, requested[p.ID]-max(p.Stock, 0), requested[p.ID]),
+			})
+			warned[p.ID] = true
+		}
+	}
+
+	res.RoundingAmount = repo.calculator.Rounding(res.TotalAmount, req.Payments)
+	if len(req.Payments) > 0 {
+		paid, change, err := settlePayments(res.TotalAmount+res.RoundingAmount, req.Payments)
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, f := range validationErr.Fields {
+				warnings = append(warnings, models.QuoteWarning{
+					Code:    models.QuoteWarningPayments,
+					Field:   f.Field,
+					Message: f.Message,
+				})
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		res.PaidAmount = paid
+		res.ChangeAmount = change
+	}
+
+	res.OutletCode = repo.numberer.Outlet(req.OutletCode)
+	res.Cashier = req.Cashier
+
+	return &models.CheckoutQuote{Transaction: res, Warnings: warnings}, nil
+}
+
+// lockProducts loads the given products with FOR UPDATE, always in id order
+// so that concurrent checkouts lock rows in the same sequence and cannot
+// deadlock each other. TaxRate holds the product's rate, or its category's
+// when the product has none.
+func lockProducts(tx *sql.Tx, ids []int) (map[int]*models.Product, error) {
+	return loadProducts(tx, ids, " FOR UPDATE OF p")
+}
+
+// loadProducts loads the given products for pricing, appending suffix to
+// the query.
+func loadProducts(q queryer, ids []int, suffix string) (map[int]*models.Product, error) {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	rows, err := q.Query(
+		`SELECT p.id, p.name, COALESCE(p.sku, ''), p.price, p.stock, p.allow_backorder, COALESCE(p.tax_rate, c.tax_rate),
+			p.parent_id, EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.id = ANY($1)
+		ORDER BY p.id`+suffix,
+		pq.Array(sorted),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[int]*models.Product, len(sorted))
+	for rows.Next() {
+		p := &models.Product{}
+		err := rows.Scan(&p.ID, &p.Name, &p.SKU, &p.Price, &p.Stock, &p.AllowBackorder, &p.TaxRate, &p.ParentID, &p.HasVariants)
 		if err != nil {
 			return nil, err
 		}
+		products[p.ID] = p
+	}
+
+	return products, rows.Err()
+}
+
+// checkItems rejects items whose product does not exist or is the parent
+// of variants, which are sold by variant only.
+func checkItems(items []models.CheckoutItem, products map[int]*models.Product) error {
+	fieldErrors := make([]models.FieldError, 0)
+	for i, item := range items {
+		p, ok := products[item.ProductID]
+		if !ok {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].product_id", i),
+				Message: fmt.Sprintf("product id %d not found", item.ProductID),
+			})
+		} else if p.HasVariants {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].product_id", i),
+				Message: fmt.Sprintf("%s comes in variants; sell one of its variants instead", p.Name),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &models.ValidationError{Message: "invalid items", Fields: fieldErrors}
+	}
+	return nil
+}
+
+// ProductIDsByBarcode returns the products the given barcodes belong to,
+// keyed by barcode. Unknown barcodes are left out.
+func (repo *TransactionRepository) ProductIDsByBarcode(codes []string) (map[string]int, error) {
+	rows, err := repo.db.Query("SELECT barcode, product_id FROM product_barcodes WHERE barcode = ANY($1)", pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int, len(codes))
+	for rows.Next() {
+		var code string
+		var id int
+		if err := rows.Scan(&code, &id); err != nil {
+			return nil, err
+		}
+		ids[code] = id
+	}
 
-		// hitung current total = quantity * pricing
-		// ditambahin ke dalam subtotal
-		subtotal := item.Quantity * price
-		totalAmount += subtotal
+	return ids, rows.Err()
+}
+
+// saleOrigin is where a sale synced from an offline till came from.
+type saleOrigin struct {
+	clientID string
+	soldAt   time.Time
+}
 
-		// kurangi jumlah stok
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, productID)
+// createTransaction runs a checkout inside tx. origin is nil for sales made
+// live; for synced sales it keeps the till's ID and the original sale time.
+func (repo *TransactionRepository) createTransaction(tx *sql.Tx, req models.CheckoutRequest, origin *saleOrigin) (*models.Transaction, error) {
+	// transaksi dicatat di shift kasir yang lagi buka; penjualan offline
+	// tetap di shift waktu dijual walaupun shiftnya sudah ditutup
+	var shiftID *int
+	var err error
+	if origin != nil {
+		shiftID, err = recordedShift(tx, req.ShiftID)
+	} else {
+		shiftID, err = resolveShift(tx, req.ShiftID, req.Cashier, repo.requireShift)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		ids = append(ids, item.ProductID)
+	}
+
+	// get product dapet pricing, sekalian dikunci biar stok nggak balapan
+	products, err := lockProducts(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkItems(req.Items, products); err != nil {
+		return nil, err
+	}
+
+	// tolak checkout kalau ada produk yang stoknya kurang
+	requested, order, shortages := checkStock(req.Items, products)
+	if len(shortages) > 0 {
+		return nil, &models.InsufficientStockError{
+			Message: "insufficient stock",
+			Items:   shortages,
+		}
+	}
+
+	// hitung harga, diskon, dan total per baris
+	res, err := repo.calculator.Price(req, products)
+	if err != nil {
+		return nil, err
+	}
+	details := res.Details
+
+	// pembulatan cuma buat bagian yang dibayar tunai
+	res.RoundingAmount = repo.calculator.Rounding(res.TotalAmount, req.Payments)
+
+	// cek pembayaran cukup buat bayar total, hitung kembalian
+	paidAmount, changeAmount, err := settlePayments(res.TotalAmount+res.RoundingAmount, req.Payments)
+	if err != nil {
+		return nil, err
+	}
+
+	// poin member: yang dipakai buat bayar dan yang didapat dari belanja
+	pointsRedeemed, pointsEarned, err := repo.settlePoints(tx, req, res.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+	var customerID *int
+	if req.CustomerID != 0 {
+		customerID = &req.CustomerID
+	}
+
+	// kasbon: yang dibayar "on_account" nggak boleh lewat limit pelanggan
+	creditAmount, err := settleCredit(tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// kurangi jumlah stok
+	for _, id := range order {
+		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", requested[id], id)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// item nya dimasukkin ke transactionDetails
-		details = append(details, models.TransactionDetail{
-			ProductID:   productID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+	// sale dari till offline pakai jam aslinya, biar laporan masuk ke hari yang bener
+	var clientID *string
+	var soldAt, syncedAt *time.Time
+	if origin != nil {
+		now := time.Now()
+		clientID, soldAt, syncedAt = &origin.clientID, &origin.soldAt, &now
 	}
 
 	// insert transaction
 	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING ID", totalAmount).Scan(&transactionID)
+	var createdAt time.Time
+	err = tx.QueryRow(
+		"INSERT INTO transactions (gross_amount, discount_amount, subtotal, tax_amount, total_amount, rounding_amount, paid_amount, change_amount, shift_id, customer_id, points_earned, points_redeemed, cashier, client_id, created_at, synced_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, COALESCE($15, NOW()), $16) RETURNING id, created_at",
+		res.GrossAmount, res.DiscountAmount, res.Subtotal, res.TaxAmount, res.TotalAmount, res.RoundingAmount, paidAmount, changeAmount, shiftID, customerID, pointsEarned, pointsRedeemed, req.Cashier, clientID, soldAt, syncedAt,
+	).Scan(&transactionID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerID != nil {
+		err = addPoints(tx, *customerID, models.PointsRedeem, -pointsRedeemed, &transactionID, nil)
+		if err != nil {
+			return nil, err
+		}
+		err = addPoints(tx, *customerID, models.PointsEarn, pointsEarned, &transactionID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if creditAmount > 0 {
+		_, err = tx.Exec(
+			"INSERT INTO receivables (customer_id, transaction_id, amount, created_at) VALUES ($1, $2, $3, $4)",
+			req.CustomerID, transactionID, creditAmount, createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// nomor invoice urut per hari (dan per outlet), diambil di dalam
+	// transaksi yang sama supaya kalau checkout gagal nomornya ikut batal
+	outlet := repo.numberer.Outlet(req.OutletCode)
+	var seq int
+	err = tx.QueryRow(`
+		INSERT INTO invoice_counters (outlet_code, day, last_seq) VALUES ($1, $2, 1)
+		ON CONFLICT (outlet_code, day) DO UPDATE SET last_seq = invoice_counters.last_seq + 1
+		RETURNING last_seq
+	`, repo.numberer.Sequence(outlet), createdAt.Format("2006-01-02")).Scan(&seq)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceNumber := repo.numberer.Number(outlet, createdAt, seq)
+	_, err = tx.Exec("UPDATE transactions SET invoice_number = $1, outlet_code = $2 WHERE id = $3", invoiceNumber, outlet, transactionID)
 	if err != nil {
 		return nil, err
 	}
 
+	// insert payments
+	payments := make([]models.Payment, 0, len(req.Payments))
+	cash := -changeAmount
+	for _, payment := range req.Payments {
+		if payment.Method == models.PaymentMethodCash {
+			cash += payment.Amount
+		}
+		p := models.Payment{
+			TransactionID: transactionID,
+			Method:        payment.Method,
+			Amount:        payment.Amount,
+			Reference:     payment.Reference,
+		}
+		err = tx.QueryRow(
+			"INSERT INTO payments (transaction_id, method, amount, reference) VALUES ($1, $2, $3, $4) RETURNING id",
+			p.TransactionID, p.Method, p.Amount, p.Reference,
+		).Scan(&p.ID)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	// penjualan offline yang masuk ke shift yang sudah ditutup: uangnya
+	// sudah ikut dihitung di laci, jadi expected cash-nya ikut dinaikkan
+	if origin != nil && shiftID != nil && cash != 0 {
+		_, err = tx.Exec(
+			"UPDATE shifts SET expected_cash = expected_cash + $1 WHERE id = $2 AND status = $3",
+			cash, *shiftID, models.ShiftStatusClosed,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// insert transaction details
 	if len(details) > 0 {
+		const columns = 13
 		valueStrings := make([]string, 0, len(details))
-		valueArgs := make([]any, 0, len(details)*4)
+		valueArgs := make([]any, 0, len(details)*columns)
 
 		for i, detail := range details {
-			base := i * 4
+			base := i * columns
 
 			valueStrings = append(valueStrings,
-				fmt.Sprintf("($%d,$%d,$%d,$%d)",
-					base+1, base+2, base+3, base+4),
+				fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+					base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8, base+9, base+10, base+11, base+12, base+13),
 			)
 
 			valueArgs = append(valueArgs,
 				transactionID,
 				detail.ProductID,
+				detail.ProductName,
+				detail.SKU,
+				detail.ParentID,
+				detail.UnitPrice,
 				detail.Quantity,
+				detail.GrossAmount,
+				detail.DiscountAmount,
 				detail.Subtotal,
+				detail.TaxRate,
+				detail.TaxAmount,
+				detail.TotalAmount,
 			)
 
 			details[i].TransactionID = transactionID
 		}
 
 		query := fmt.Sprintf(
-			"INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES %s",
+			"INSERT INTO transaction_details (transaction_id, product_id, product_name, sku, parent_id, unit_price, quantity, gross_amount, discount_amount, subtotal, tax_rate, tax_amount, total_amount) VALUES %s RETURNING id",
 			strings.Join(valueStrings, ","),
 		)
 
-		_, err = tx.Exec(query, valueArgs...)
+		rows, err := tx.Query(query, valueArgs...)
 		if err != nil {
 			return nil, err
 		}
+		defer rows.Close()
+
+		// id detail balik sesuai urutan VALUES
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&details[i].ID); err != nil {
+				return nil, err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	res.ID = transactionID
+	res.InvoiceNumber = invoiceNumber
+	res.OutletCode = outlet
+	res.PaidAmount = paidAmount
+	res.ChangeAmount = changeAmount
+	res.Status = models.TransactionStatusCompleted
+	res.ShiftID = shiftID
+	res.CustomerID = customerID
+	res.PointsEarned = pointsEarned
+	res.PointsRedeemed = pointsRedeemed
+	res.Cashier = req.Cashier
+	res.CreatedAt = createdAt
+	if origin != nil {
+		res.ClientID = origin.clientID
+		res.SyncedAt = syncedAt
 	}
+	res.Payments = payments
 
-	if err := tx.Commit(); err != nil {
+	return res, nil
+}
+
+// settlePoints locks the customer of a checkout and works out the points
+// spent through "points" payments and the points earned on the rest of
+// total. Points can only be spent by a customer who has enough of them.
+func (repo *TransactionRepository) settlePoints(tx *sql.Tx, req models.CheckoutRequest, total int) (redeemed, earned int, err error) {
+	pointsAmount := 0
+	for _, payment := range req.Payments {
+		if payment.Method == models.PaymentMethodPoints {
+			pointsAmount += payment.Amount
+		}
+	}
+
+	if req.CustomerID == 0 {
+		if pointsAmount > 0 {
+			return 0, 0, &models.ValidationError{
+				Message: "invalid payments",
+				Fields:  []models.FieldError{{Field: "customer_id", Message: "a customer is required to pay with points"}},
+			}
+		}
+		return 0, 0, nil
+	}
+
+	balance, err := lockCustomer(tx, req.CustomerID)
+	var notFoundErr *models.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return 0, 0, &models.ValidationError{
+			Message: "invalid customer",
+			Fields:  []models.FieldError{{Field: "customer_id", Message: err.Error()}},
+		}
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	redeemed, err = repo.loyalty.Redeemed(pointsAmount)
+	if err == nil && redeemed > balance {
+		err = fmt.Errorf("customer has only %d points", balance)
+	}
+	if err != nil {
+		return 0, 0, &models.ValidationError{
+			Message: "invalid payments",
+			Fields:  []models.FieldError{{Field: "payments", Message: err.Error()}},
+		}
+	}
+
+	return redeemed, repo.loyalty.Earned(total - pointsAmount), nil
+}
+
+// checkStock adds up the quantity asked for each product and reports every
+// product that does not have enough stock. order lists the product IDs in
+// the order they first appear in items.
+func checkStock(items []models.CheckoutItem, products map[int]*models.Product) (requested map[int]int, order []int, shortages []models.StockShortage) {
+	requested = make(map[int]int)
+	order = make([]int, 0)
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	shortages = make([]models.StockShortage, 0)
+	for _, id := range order {
+		p := products[id]
+		if !p.AllowBackorder && requested[id] > p.Stock {
+			shortages = append(shortages, models.StockShortage{
+				ProductID:   p.ID,
+				ProductName: p.Name,
+				Requested:   requested[id],
+				Available:   p.Stock,
+			})
+		}
+	}
+
+	return requested, order, shortages
+}
+
+// settlePayments checks that payments cover total and works out the change.
+// Only cash can be overpaid; card and e-wallet payments never produce change.
+func settlePayments(total int, payments []models.CheckoutPayment) (paid, change int, err error) {
+	nonCash := 0
+	for _, payment := range payments {
+		paid += payment.Amount
+		if payment.Method != models.PaymentMethodCash {
+			nonCash += payment.Amount
+		}
+	}
+
+	if nonCash > total {
+		return 0, 0, &models.ValidationError{
+			Message: "invalid payments",
+			Fields: []models.FieldError{{
+				Field:   "payments",
+				Message: fmt.Sprintf("non-cash payments of %d exceed the total of %d", nonCash, total),
+			}},
+		}
+	}
+
+	if paid < total {
+		return 0, 0, &models.ValidationError{
+			Message: "invalid payments",
+			Fields: []models.FieldError{{
+				Field:   "payments",
+				Message: fmt.Sprintf("payments of %d do not cover the total of %d", paid, total),
+			}},
+		}
+	}
+
+	return paid, paid - total, nil
+}
+
+// GetAll returns one page of transactions matching filter, newest first,
+// together with the total number of matching transactions.
+func (repo *TransactionRepository) GetAll(filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.StartDate != "" {
+		addCondition("DATE(t.created_at) >= $%d", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		addCondition("DATE(t.created_at) <= $%d", filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		addCondition("t.total_amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("t.total_amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.ProductID != 0 {
+		addCondition("EXISTS (SELECT 1 FROM transaction_details td WHERE td.transaction_id = t.id AND td.product_id = $%d)", filter.ProductID)
+	}
+	if filter.InvoiceNumber != "" {
+		addCondition(`t.invoice_number ILIKE $%d ESCAPE '\'`, "%"+likeEscaper.Replace(filter.InvoiceNumber)+"%")
+	}
+	if filter.PaymentMethod != "" {
+		addCondition("EXISTS (SELECT 1 FROM payments pm WHERE pm.transaction_id = t.id AND pm.method = $%d)", filter.PaymentMethod)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM transactions t"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		"SELECT t.id, COALESCE(t.invoice_number, ''), t.outlet_code, t.gross_amount, t.discount_amount, t.subtotal, t.tax_amount, t.total_amount, t.rounding_amount, t.paid_amount, t.change_amount, t.status, t.shift_id, t.customer_id, t.points_earned, t.points_redeemed, t.cashier, COALESCE(t.client_id::text, ''), t.created_at, t.synced_at FROM transactions t%s ORDER BY t.created_at DESC, t.id DESC LIMIT $%d OFFSET $%d",
+		where, len(args)+1, len(args)+2,
+	)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(&t.ID, &t.InvoiceNumber, &t.OutletCode, &t.GrossAmount, &t.DiscountAmount, &t.Subtotal, &t.TaxAmount, &t.TotalAmount, &t.RoundingAmount, &t.PaidAmount, &t.ChangeAmount, &t.Status, &t.ShiftID, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed, &t.Cashier, &t.ClientID, &t.CreatedAt, &t.SyncedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+		ids = append(ids, t.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	details, err := repo.getDetails(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	payments, err := repo.getPayments(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range transactions {
+		transactions[i].Details = details[transactions[i].ID]
+		transactions[i].Payments = payments[transactions[i].ID]
+	}
+
+	return transactions, total, nil
+}
+
+// GetByID - ambil transaction by ID beserta detailnya
+func (repo *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	query := "SELECT id, COALESCE(invoice_number, ''), outlet_code, gross_amount, discount_amount, subtotal, tax_amount, total_amount, rounding_amount, paid_amount, change_amount, status, shift_id, customer_id, points_earned, points_redeemed, cashier, COALESCE(client_id::text, ''), created_at, synced_at FROM transactions WHERE id = $1"
+
+	var t models.Transaction
+	err := repo.db.QueryRow(query, id).Scan(&t.ID, &t.InvoiceNumber, &t.OutletCode, &t.GrossAmount, &t.DiscountAmount, &t.Subtotal, &t.TaxAmount, &t.TotalAmount, &t.RoundingAmount, &t.PaidAmount, &t.ChangeAmount, &t.Status, &t.ShiftID, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed, &t.Cashier, &t.ClientID, &t.CreatedAt, &t.SyncedAt)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Resource: "Transaction"}
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	res = &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
+	details, err := repo.getDetails([]int{t.ID})
+	if err != nil {
+		return nil, err
 	}
+	t.Details = details[t.ID]
 
-	return res, nil
+	payments, err := repo.getPayments([]int{t.ID})
+	if err != nil {
+		return nil, err
+	}
+	t.Payments = payments[t.ID]
+
+	return &t, nil
+}
+
+// getDetails loads the detail lines of the given transactions, keyed by transaction ID.
+func (repo *TransactionRepository) getDetails(transactionIDs []int) (map[int][]models.TransactionDetail, error) {
+	details := make(map[int][]models.TransactionDetail, len(transactionIDs))
+	for _, id := range transactionIDs {
+		details[id] = make([]models.TransactionDetail, 0)
+	}
+	if len(transactionIDs) == 0 {
+		return details, nil
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT td.id, td.transaction_id, td.product_id, td.product_name, td.sku, td.parent_id, td.unit_price, td.quantity,
+			COALESCE((SELECT SUM(rd.quantity) FROM refund_details rd WHERE rd.transaction_detail_id = td.id), 0),
+			td.gross_amount, td.discount_amount, td.subtotal, td.tax_rate, td.tax_amount, td.total_amount
+		FROM transaction_details td
+		WHERE td.transaction_id = ANY($1)
+		ORDER BY td.id
+	`, pq.Array(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.TransactionDetail
+		err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.SKU, &d.ParentID, &d.UnitPrice, &d.Quantity, &d.RefundedQuantity, &d.GrossAmount, &d.DiscountAmount, &d.Subtotal, &d.TaxRate, &d.TaxAmount, &d.TotalAmount)
+		if err != nil {
+			return nil, err
+		}
+		details[d.TransactionID] = append(details[d.TransactionID], d)
+	}
+
+	return details, rows.Err()
+}
+
+// getPayments loads the payments of the given transactions, keyed by transaction ID.
+func (repo *TransactionRepository) getPayments(transactionIDs []int) (map[int][]models.Payment, error) {
+	payments := make(map[int][]models.Payment, len(transactionIDs))
+	for _, id := range transactionIDs {
+		payments[id] = make([]models.Payment, 0)
+	}
+	if len(transactionIDs) == 0 {
+		return payments, nil
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT id, transaction_id, method, amount, reference
+		FROM payments
+		WHERE transaction_id = ANY($1)
+		ORDER BY id
+	`, pq.Array(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.Payment
+		err := rows.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.Reference)
+		if err != nil {
+			return nil, err
+		}
+		payments[p.TransactionID] = append(payments[p.TransactionID], p)
+	}
+
+	return payments, rows.Err()
 }
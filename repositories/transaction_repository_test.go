@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"kasir-api/database"
+	"kasir-api/invoice"
+	"kasir-api/loyalty"
+	"kasir-api/models"
+	"kasir-api/pricing"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL and migrates it.
+// Tests that need Postgres are skipped when it is not set.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.InitDB(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testTransactionRepository builds a transaction repository without tax,
+// rounding or shifts.
+func testTransactionRepository(t *testing.T, db *sql.DB) *TransactionRepository {
+	t.Helper()
+
+	calculator := pricing.NewCalculator(pricing.Calculator{MaxDiscountPercent: 100, CashRounding: pricing.RoundingNone})
+	numberer, err := invoice.NewNumberer("", "TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := loyalty.NewProgram(loyalty.Program{PointValue: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTransactionRepository(db, calculator, numberer, program, false)
+}
+
+// testProduct adds a product without its own tax rate in a new category.
+func testProduct(t *testing.T, db *sql.DB, price, stock int) int {
+	t.Helper()
+
+	name := fmt.Sprintf("%s %d", t.Name(), time.Now().UnixNano())
+	var categoryID, productID int
+	err := db.QueryRow("INSERT INTO categories (name) VALUES ($1) RETURNING id", name).Scan(&categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(
+		"INSERT INTO products (name, price, stock, category_id, tax_rate) VALUES ($1, $2, $3, $4, 0) RETURNING id",
+		name, price, stock, categoryID,
+	).Scan(&productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return productID
+}
+
+func TestInvoiceNumberSequence(t *testing.T) {
+	db := testDB(t)
+
+	calculator := pricing.NewCalculator(pricing.Calculator{MaxDiscountPercent: 100, CashRounding: pricing.RoundingNone})
+	numberer, err := invoice.NewNumberer("{outlet}/{date}/{seq:3}", "MAIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := loyalty.NewProgram(loyalty.Program{PointValue: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewTransactionRepository(db, calculator, numberer, program, false)
+	productID := testProduct(t, db, 1000, 10)
+
+	// outlet baru tiap run, jadi counternya selalu mulai dari nol
+	run := time.Now().UnixNano()
+	outletA, outletB := fmt.Sprintf("A%d", run), fmt.Sprintf("B%d", run)
+	day1 := time.Date(2026, 3, 7, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+
+	tests := []struct {
+		outlet string
+		soldAt time.Time
+		want   string
+	}{
+		{outlet: outletA, soldAt: day1, want: outletA + "/20260307/001"},
+		{outlet: outletA, soldAt: day1, want: outletA + "/20260307/002"},
+		{outlet: outletB, soldAt: day1, want: outletB + "/20260307/001"},
+		{outlet: outletA, soldAt: day2, want: outletA + "/20260308/001"},
+		{outlet: outletA, soldAt: day1, want: outletA + "/20260307/003"},
+	}
+
+	for i, tt := range tests {
+		res, _, err := repo.Sync(models.SyncTransaction{
+			ClientID:  fmt.Sprintf("00000000-0000-4000-8000-%012d", (run+int64(i))%1e12),
+			CreatedAt: tt.soldAt,
+			CheckoutRequest: models.CheckoutRequest{
+				OutletCode: tt.outlet,
+				Items:      []models.CheckoutItem{{ProductID: productID, Quantity: 1}},
+				Payments:   []models.CheckoutPayment{{Method: models.PaymentMethodCash, Amount: 1000}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("sale %d: %v", i, err)
+		}
+		if res.InvoiceNumber != tt.want {
+			t.Errorf("sale %d invoice = %q, want %q", i, res.InvoiceNumber, tt.want)
+		}
+	}
+}
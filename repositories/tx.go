@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxTxAttempts bounds how many times a transaction is run when Postgres
+// aborts it because of a serialization failure or a deadlock.
+const maxTxAttempts = 5
+
+// withTx runs fn inside a database transaction and commits it. When the
+// transaction is aborted by a serialization failure or deadlock, the whole
+// function is retried from scratch with a short randomized backoff.
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = runTx(db, fn)
+		if !isRetryableTxError(err) {
+			return err
+		}
+
+		backoff := time.Duration(attempt*10+rand.IntN(20)) * time.Millisecond
+		time.Sleep(backoff)
+	}
+
+	return err
+}
+
+func runTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,28 @@
+// Package rupiah formats amounts of money the way they are printed on
+// receipts and shelf labels.
+package rupiah
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format writes amount with dots as thousand separators, e.g. 10.500 or
+// -1.250.
+func Format(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,28 @@
+package rupiah
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount int
+		want   string
+	}{
+		{amount: 0, want: "0"},
+		{amount: 999, want: "999"},
+		{amount: 1000, want: "1.000"},
+		{amount: 10500, want: "10.500"},
+		{amount: 1234567, want: "1.234.567"},
+		{amount: -50, want: "-50"},
+		{amount: -500, want: "-500"},
+		{amount: -1500, want: "-1.500"},
+		{amount: -123456, want: "-123.456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Format(tt.amount); got != tt.want {
+				t.Errorf("Format(%d) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"kasir-api/models"
 	"kasir-api/repositories"
 )
@@ -18,6 +19,10 @@ func (s *CategoryService) GetAll() ([]models.Category, error) {
 }
 
 func (s *CategoryService) Create(data *models.Category) error {
+	if err := validateTaxRate(data.TaxRate); err != nil {
+		return err
+	}
+
 	return s.repo.Create(data)
 }
 
@@ -26,9 +31,21 @@ func (s *CategoryService) GetByID(id int) (*models.Category, error) {
 }
 
 func (s *CategoryService) Update(category *models.Category) error {
+	if err := validateTaxRate(category.TaxRate); err != nil {
+		return err
+	}
+
 	return s.repo.Update(category)
 }
 
 func (s *CategoryService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+// validateTaxRate checks an optional tax rate override, in percent.
+func validateTaxRate(rate *float64) error {
+	if rate != nil && (*rate < 0 || *rate > 100) {
+		return errors.New("tax_rate must be between 0 and 100")
+	}
+	return nil
+}
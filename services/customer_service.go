@@ -0,0 +1,122 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/mail"
+	"slices"
+	"strings"
+)
+
+type CustomerService struct {
+	repo        *repositories.CustomerRepository
+	receivables *repositories.ReceivableRepository
+}
+
+func NewCustomerService(repo *repositories.CustomerRepository, receivables *repositories.ReceivableRepository) *CustomerService {
+	return &CustomerService{repo: repo, receivables: receivables}
+}
+
+// GetAll searches customers by name or phone. Phone numbers are stored
+// normalised, so the search is normalised the same way to match them.
+func (s *CustomerService) GetAll(search string) ([]models.Customer, error) {
+	return s.repo.GetAll(search, normalisePhone(search))
+}
+
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetByPhone finds a customer by phone number, however it is written.
+func (s *CustomerService) GetByPhone(phone string) (*models.Customer, error) {
+	return s.repo.GetByPhone(normalisePhone(phone))
+}
+
+func (s *CustomerService) Create(customer *models.Customer) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+
+	return s.repo.Create(customer)
+}
+
+func (s *CustomerService) Update(customer *models.Customer) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+
+	return s.repo.Update(customer)
+}
+
+func (s *CustomerService) GetPoints(id int) (*models.PointsHistory, error) {
+	return s.repo.GetPoints(id)
+}
+
+func (s *CustomerService) GetReceivables(id int) (*models.CustomerReceivables, error) {
+	return s.receivables.GetByCustomer(id)
+}
+
+// Repay records money a customer pays towards their debt.
+func (s *CustomerService) Repay(id int, req models.RepaymentRequest) (*models.Repayment, error) {
+	req.Cashier = strings.TrimSpace(req.Cashier)
+
+	fieldErrors := make([]models.FieldError, 0)
+	if req.Amount <= 0 {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "amount", Message: "amount must be greater than 0"})
+	}
+	if !slices.Contains(models.RepaymentMethods, req.Method) {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "method",
+			Message: "method must be one of " + strings.Join(models.RepaymentMethods, ", "),
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid repayment", Fields: fieldErrors}
+	}
+
+	return s.receivables.Repay(id, req)
+}
+
+// validateCustomer checks a customer and normalises their phone number.
+func validateCustomer(customer *models.Customer) error {
+	customer.Name = strings.TrimSpace(customer.Name)
+	customer.Phone = normalisePhone(customer.Phone)
+	customer.Email = strings.TrimSpace(customer.Email)
+
+	fieldErrors := make([]models.FieldError, 0)
+	if customer.Name == "" {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "name", Message: "name is required"})
+	}
+	if len(customer.Phone) < 8 {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "phone", Message: "phone must have at least 8 digits"})
+	}
+	if customer.CreditLimit < 0 {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "credit_limit", Message: "credit_limit must not be negative"})
+	}
+	if customer.Email != "" {
+		if _, err := mail.ParseAddress(customer.Email); err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: "email", Message: "email is not valid"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &models.ValidationError{Message: "invalid customer", Fields: fieldErrors}
+	}
+
+	return nil
+}
+
+// normalisePhone keeps only the digits of phone and writes Indonesian
+// numbers in their local form, so +62 812-3456 and 0812 3456 are the same.
+func normalisePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+
+	if rest, ok := strings.CutPrefix(digits, "62"); ok {
+		return "0" + rest
+	}
+	return digits
+}
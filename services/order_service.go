@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+)
+
+var orderStatuses = []string{models.OrderStatusOpen, models.OrderStatusSettled, models.OrderStatusCancelled}
+
+type OrderService struct {
+	repo     *repositories.OrderRepository
+	checkout *TransactionService
+}
+
+// NewOrderService creates the order service. Orders are settled through
+// checkout, so its item rules and quantity limits apply to orders too.
+func NewOrderService(repo *repositories.OrderRepository, checkout *TransactionService) *OrderService {
+	return &OrderService{repo: repo, checkout: checkout}
+}
+
+func (s *OrderService) Create(req models.OrderRequest) (*models.Order, error) {
+	fieldErrors := make([]models.FieldError, 0)
+	for i, item := range req.Items {
+		fieldErrors = append(fieldErrors, s.validateOrderItem(item, fmt.Sprintf("items[%d].", i))...)
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid order items", Fields: fieldErrors}
+	}
+
+	return s.repo.Create(req)
+}
+
+// GetAll lists orders with the given status; an empty status lists the
+// open ones.
+func (s *OrderService) GetAll(status string) ([]models.Order, error) {
+	if status == "" {
+		status = models.OrderStatusOpen
+	}
+	if !slices.Contains(orderStatuses, status) {
+		return nil, &models.ValidationError{
+			Message: "invalid status",
+			Fields:  []models.FieldError{{Field: "status", Message: "status must be open, settled or cancelled"}},
+		}
+	}
+
+	return s.repo.GetAll(status)
+}
+
+func (s *OrderService) GetByID(id int) (*models.Order, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OrderService) Update(id int, req models.OrderRequest) (*models.Order, error) {
+	return s.repo.Update(id, req.Label, req.Cashier)
+}
+
+func (s *OrderService) AddItem(orderID int, item models.OrderItemRequest) (*models.Order, error) {
+	if fieldErrors := s.validateOrderItem(item, ""); len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid order item", Fields: fieldErrors}
+	}
+
+	return s.repo.AddItem(orderID, item)
+}
+
+func (s *OrderService) UpdateItem(orderID, itemID int, item models.OrderItemRequest) (*models.Order, error) {
+	if fieldErrors := s.validateOrderItem(item, ""); len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid order item", Fields: fieldErrors}
+	}
+
+	return s.repo.UpdateItem(orderID, itemID, item)
+}
+
+func (s *OrderService) DeleteItem(orderID, itemID int) (*models.Order, error) {
+	return s.repo.DeleteItem(orderID, itemID)
+}
+
+func (s *OrderService) Cancel(id int) (*models.Order, error) {
+	return s.repo.Cancel(id)
+}
+
+// Settle checks out an open order. Stock, prices and discounts are checked
+// exactly as for a direct checkout.
+func (s *OrderService) Settle(id int, req models.SettleOrderRequest) (*models.Transaction, error) {
+	if err := validatePayments(req.Payments); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Settle(id, req, s.checkout.normaliseItems)
+}
+
+// validateOrderItem checks the parts of an item that do not need the
+// database. Discounts are checked against the price, and the quantity of
+// lines for the same product together, when the order is settled.
+func (s *OrderService) validateOrderItem(item models.OrderItemRequest, prefix string) []models.FieldError {
+	fieldErrors := make([]models.FieldError, 0)
+	maxQuantity := s.checkout.limits.MaxItemQuantity
+	if item.Quantity <= 0 {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   prefix + "quantity",
+			Message: "quantity must be greater than 0",
+		})
+	} else if maxQuantity > 0 && item.Quantity > maxQuantity {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   prefix + "quantity",
+			Message: fmt.Sprintf("quantity of %d exceeds the maximum of %d", item.Quantity, maxQuantity),
+		})
+	}
+	if item.Discount != nil && item.Discount.Type != models.DiscountTypePercent && item.Discount.Type != models.DiscountTypeFixed {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   prefix + "discount",
+			Message: fmt.Sprintf("discount type must be %q or %q", models.DiscountTypePercent, models.DiscountTypeFixed),
+		})
+	}
+	return fieldErrors
+}
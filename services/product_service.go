@@ -2,27 +2,162 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"kasir-api/barcode"
+	"kasir-api/label"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"slices"
+	"strings"
+)
+
+const maxSKULength = 64
+
+// Limits on how a product may vary.
+const (
+	maxVariantOptions      = 3
+	maxVariantOptionValues = 50
+)
+
+// Limits for one label print job.
+const (
+	maxLabelCopies = 100
+	maxLabels      = 2000
+)
+
+const (
+	defaultProductPageSize = 50
+	maxProductPageSize     = 500
+
+	defaultSearchLimit     = 20
+	maxSearchLimit         = 100
+	defaultSuggestionLimit = 8
+	maxSuggestionLimit     = 20
+	maxSearchQueryLength   = 100
 )
 
 type ProductService struct {
-	repo         *repositories.ProductRepository
-	categoryRepo *repositories.CategoryRepository
+	repo              *repositories.ProductRepository
+	categoryRepo      *repositories.CategoryRepository
+	lowStockThreshold int
 }
 
-func NewProductService(repo *repositories.ProductRepository, categoryRepo *repositories.CategoryRepository) *ProductService {
+// NewProductService creates the product service. Products with stock above
+// zero and at or under lowStockThreshold count as low on stock.
+func NewProductService(repo *repositories.ProductRepository, categoryRepo *repositories.CategoryRepository, lowStockThreshold int) *ProductService {
 	return &ProductService{
-		repo:         repo,
-		categoryRepo: categoryRepo,
+		repo:              repo,
+		categoryRepo:      categoryRepo,
+		lowStockThreshold: lowStockThreshold,
+	}
+}
+
+// GetAll returns one page of the product listing, sorted by id unless
+// filter says otherwise.
+func (s *ProductService) GetAll(filter models.ProductFilter) (*models.ProductPage, error) {
+	var fields []models.FieldError
+
+	if filter.Sort == "" {
+		filter.Sort = models.ProductSortID
+	}
+	switch filter.Sort {
+	case models.ProductSortName, models.ProductSortPrice, models.ProductSortStock, models.ProductSortID:
+	default:
+		fields = append(fields, models.FieldError{Field: "sort", Message: "sort must be name, price, stock or id"})
+	}
+
+	switch filter.Stock {
+	case "", models.StockIn, models.StockOut, models.StockLow:
+	default:
+		fields = append(fields, models.FieldError{Field: "stock", Message: "stock must be in, out or low"})
+	}
+	filter.LowStockThreshold = s.lowStockThreshold
+
+	if filter.MinPrice != nil && *filter.MinPrice < 0 {
+		fields = append(fields, models.FieldError{Field: "min_price", Message: "min_price must not be negative"})
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		fields = append(fields, models.FieldError{Field: "max_price", Message: "max_price must not be less than min_price"})
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxProductPageSize {
+		fields = append(fields, models.FieldError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxProductPageSize)})
 	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultProductPageSize
+	}
+
+	if len(fields) > 0 {
+		return nil, &models.ValidationError{Message: "invalid product query", Fields: fields}
+	}
+
+	return s.repo.GetAll(filter)
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+// Search finds products by name, SKU, barcode or category, tolerating typos
+// and words in any order, best match first.
+func (s *ProductService) Search(q string, limit int) ([]models.ProductMatch, error) {
+	return s.search(q, limit, defaultSearchLimit, maxSearchLimit)
+}
+
+// Autocomplete returns the top suggestions for what a cashier has typed so
+// far, with just enough of each product to show in a dropdown.
+func (s *ProductService) Autocomplete(q string, limit int) ([]models.ProductSuggestion, error) {
+	matches, err := s.search(q, limit, defaultSuggestionLimit, maxSuggestionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]models.ProductSuggestion, len(matches))
+	for i, m := range matches {
+		suggestions[i] = models.ProductSuggestion{ID: m.ID, Name: m.Name, SKU: m.SKU, Price: m.Price, Stock: m.Stock}
+	}
+	return suggestions, nil
+}
+
+func (s *ProductService) search(q string, limit, defaultLimit, maxLimit int) ([]models.ProductMatch, error) {
+	var fields []models.FieldError
+
+	q = strings.ToLower(strings.Join(strings.Fields(q), " "))
+	if q == "" {
+		fields = append(fields, models.FieldError{Field: "q", Message: "q is required"})
+	}
+	if len(q) > maxSearchQueryLength {
+		fields = append(fields, models.FieldError{Field: "q", Message: fmt.Sprintf("q must be at most %d characters", maxSearchQueryLength)})
+	}
+	if limit < 0 || limit > maxLimit {
+		fields = append(fields, models.FieldError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxLimit)})
+	}
+	if len(fields) > 0 {
+		return nil, &models.ValidationError{Message: "invalid search", Fields: fields}
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	// kalau yang diketik/scan itu barcode, cocokkan juga persis
+	code, err := barcode.Normalize(q)
+	if err != nil {
+		code = ""
+	}
+
+	return s.repo.Search(q, code, limit)
 }
 
 func (s *ProductService) Create(data *models.Product) error {
+	if data.ParentID != nil || len(data.Options) > 0 {
+		return errors.New("variants are added with POST /api/products/{id}/variants")
+	}
+	if err := validateTaxRate(data.TaxRate); err != nil {
+		return err
+	}
+	if err := normaliseCodes(data); err != nil {
+		return err
+	}
+	if err := normaliseVariantOptions(data.VariantOptions); err != nil {
+		return err
+	}
+
 	exists, err := s.categoryRepo.Exists(data.CategoryID)
 	if err != nil {
 		return err
@@ -38,10 +173,259 @@ func (s *ProductService) GetByID(id int) (*models.Product, error) {
 	return s.repo.GetByID(id)
 }
 
+// GetVariants lists the variants of a product.
+func (s *ProductService) GetVariants(parentID int) ([]models.Product, error) {
+	if _, err := s.repo.GetByID(parentID); err != nil {
+		return nil, &models.NotFoundError{Resource: "Product"}
+	}
+	return s.repo.GetVariants(parentID)
+}
+
+// CreateVariant adds a variant with its own SKU, barcodes, price and stock
+// to a product that has variant options.
+func (s *ProductService) CreateVariant(parentID int, variant *models.Product) error {
+	if err := validateTaxRate(variant.TaxRate); err != nil {
+		return &models.ValidationError{
+			Message: "invalid variant",
+			Fields:  []models.FieldError{{Field: "tax_rate", Message: err.Error()}},
+		}
+	}
+	if err := normaliseCodes(variant); err != nil {
+		return &models.ValidationError{Message: err.Error()}
+	}
+	variant.Name = strings.TrimSpace(variant.Name)
+
+	values := make(models.VariantValues, len(variant.Options))
+	for name, value := range variant.Options {
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	variant.Options = values
+
+	return s.repo.CreateVariant(parentID, variant)
+}
+
+// GetByBarcode finds the product a scanned code belongs to. A UPC-A code
+// finds the product stored under the same EAN-13 and the other way round.
+func (s *ProductService) GetByBarcode(code string) (*models.Product, error) {
+	code, err := barcode.Normalize(code)
+	if err != nil {
+		return nil, &models.ValidationError{
+			Message: "invalid barcode",
+			Fields:  []models.FieldError{{Field: "code", Message: err.Error()}},
+		}
+	}
+
+	return s.repo.GetByBarcode(code)
+}
+
+// AssignBarcode gives a product without a barcode an internal EAN-13 code.
+func (s *ProductService) AssignBarcode(id int) (*models.Product, bool, error) {
+	return s.repo.AssignBarcode(id)
+}
+
+// BarcodeImage draws one of a product's codes as a barcode image. code
+// defaults to the product's first barcode, then its SKU; symbology
+// defaults to EAN-13 for 13 digit codes and Code 128 for anything else.
+func (s *ProductService) BarcodeImage(id int, code, symbology, format string, opts barcode.Options) ([]byte, error) {
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == "" {
+		if len(product.Barcodes) > 0 {
+			code = product.Barcodes[0]
+		} else {
+			code = product.SKU
+		}
+	} else if code != product.SKU {
+		code, _ = barcode.Normalize(code)
+		if !slices.Contains(product.Barcodes, code) {
+			return nil, &models.ValidationError{
+				Message: "invalid barcode",
+				Fields:  []models.FieldError{{Field: "code", Message: "code is not a barcode or SKU of this product"}},
+			}
+		}
+	}
+	if code == "" {
+		return nil, &models.ValidationError{
+			Message: "invalid barcode",
+			Fields:  []models.FieldError{{Field: "code", Message: "product has no barcode or SKU; assign a barcode first"}},
+		}
+	}
+
+	if symbology == "" {
+		symbology = barcode.TypeCode128
+		if len(code) == 13 && barcode.IsGTIN(code) {
+			symbology = barcode.TypeEAN13
+		}
+	}
+	symbol, err := barcode.Encode(symbology, code)
+	if err != nil {
+		return nil, &models.ValidationError{
+			Message: "invalid barcode",
+			Fields:  []models.FieldError{{Field: "type", Message: err.Error()}},
+		}
+	}
+
+	if format == barcode.FormatSVG {
+		return symbol.SVG(opts), nil
+	}
+	return symbol.PNG(opts)
+}
+
+// Labels renders shelf price labels for the products picked by filter as an
+// A4 PDF. layout is columns x rows per sheet and defaults to 3x8; each
+// product gets copies labels.
+func (s *ProductService) Labels(filter models.LabelFilter, layout string, copies int) ([]byte, error) {
+	var fields []models.FieldError
+	selectors := 0
+	if len(filter.IDs) > 0 {
+		selectors++
+	}
+	if filter.CategoryID != 0 {
+		selectors++
+	}
+	if filter.ChangedSince != "" {
+		selectors++
+	}
+	if selectors != 1 {
+		fields = append(fields, models.FieldError{Field: "ids", Message: "give exactly one of ids, category_id or changed_since"})
+	}
+
+	if layout == "" {
+		layout = label.DefaultLayout
+	}
+	grid, err := label.ParseLayout(layout)
+	if err != nil {
+		fields = append(fields, models.FieldError{Field: "layout", Message: err.Error()})
+	}
+
+	if copies == 0 {
+		copies = 1
+	}
+	if copies < 1 || copies > maxLabelCopies {
+		fields = append(fields, models.FieldError{Field: "copies", Message: fmt.Sprintf("copies must be between 1 and %d", maxLabelCopies)})
+	}
+	if len(fields) > 0 {
+		return nil, &models.ValidationError{Message: "invalid label request", Fields: fields}
+	}
+
+	if filter.CategoryID != 0 {
+		exists, err := s.categoryRepo.Exists(filter.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, &models.NotFoundError{Resource: "Category"}
+		}
+	}
+
+	products, err := s.repo.GetForLabels(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// id yang nggak ketemu dilaporkan semua sekaligus
+	for _, id := range filter.IDs {
+		found := slices.ContainsFunc(products, func(p models.Product) bool { return p.ID == id })
+		if !found {
+			fields = append(fields, models.FieldError{Field: "ids", Message: fmt.Sprintf("product %d is not found", id)})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, &models.ValidationError{Message: "invalid label request", Fields: fields}
+	}
+	if len(products) == 0 {
+		return nil, &models.ValidationError{Message: "no products to print labels for"}
+	}
+	if len(products)*copies > maxLabels {
+		return nil, &models.ValidationError{
+			Message: fmt.Sprintf("at most %d labels can be printed at once; pick fewer products or copies", maxLabels),
+		}
+	}
+
+	return label.PDF(products, grid, copies)
+}
+
 func (s *ProductService) Update(product *models.Product) error {
+	if err := validateTaxRate(product.TaxRate); err != nil {
+		return err
+	}
+	if err := normaliseCodes(product); err != nil {
+		return err
+	}
+	if err := normaliseVariantOptions(product.VariantOptions); err != nil {
+		return err
+	}
+
 	return s.repo.Update(product)
 }
 
 func (s *ProductService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+// normaliseVariantOptions trims option names and values and checks that
+// none is empty or listed twice.
+func normaliseVariantOptions(options []models.VariantOption) error {
+	if len(options) > maxVariantOptions {
+		return fmt.Errorf("a product can vary in at most %d ways", maxVariantOptions)
+	}
+
+	names := make([]string, 0, len(options))
+	for i := range options {
+		o := &options[i]
+		o.Name = strings.TrimSpace(o.Name)
+		if o.Name == "" {
+			return errors.New("variant option name is required")
+		}
+		if slices.Contains(names, o.Name) {
+			return fmt.Errorf("variant option %s is listed twice", o.Name)
+		}
+		names = append(names, o.Name)
+
+		if len(o.Values) == 0 || len(o.Values) > maxVariantOptionValues {
+			return fmt.Errorf("variant option %s must have 1 to %d values", o.Name, maxVariantOptionValues)
+		}
+		values := make([]string, 0, len(o.Values))
+		for _, v := range o.Values {
+			v = strings.TrimSpace(v)
+			if v == "" || slices.Contains(values, v) {
+				return fmt.Errorf("values of variant option %s must be unique and not empty", o.Name)
+			}
+			values = append(values, v)
+		}
+		o.Values = values
+	}
+	return nil
+}
+
+// normaliseCodes checks a product's SKU and barcodes and writes barcodes in
+// the form they are looked up in, dropping any listed twice.
+func normaliseCodes(product *models.Product) error {
+	product.SKU = strings.TrimSpace(product.SKU)
+	if len(product.SKU) > maxSKULength {
+		return fmt.Errorf("sku must be at most %d characters", maxSKULength)
+	}
+	if strings.ContainsFunc(product.SKU, func(r rune) bool { return r <= ' ' }) {
+		return errors.New("sku must not contain spaces")
+	}
+
+	if product.Barcodes == nil {
+		return nil
+	}
+	codes := make([]string, 0, len(product.Barcodes))
+	for _, code := range product.Barcodes {
+		code, err := barcode.Normalize(code)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	product.Barcodes = codes
+
+	return nil
+}
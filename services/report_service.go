@@ -20,3 +20,22 @@ func (s *ReportService) GetTodayReport() (*models.TodayReport, error) {
 func (s *ReportService) GetReportByDateRange(startDate, endDate string) (*models.TodayReport, error) {
 	return s.repo.GetReportByDateRange(startDate, endDate)
 }
+
+// GetProductSales reports sales per product, or per parent product with
+// its variants added up when groupBy is "parent".
+func (s *ReportService) GetProductSales(startDate, endDate, groupBy string) (*models.ProductSalesReport, error) {
+	if groupBy == "" {
+		groupBy = models.GroupByProduct
+	}
+	if groupBy != models.GroupByProduct && groupBy != models.GroupByParent {
+		return nil, &models.ValidationError{
+			Message: "invalid report",
+			Fields:  []models.FieldError{{Field: "group_by", Message: "group_by must be product or parent"}},
+		}
+	}
+	return s.repo.GetProductSales(startDate, endDate, groupBy)
+}
+
+func (s *ReportService) GetReceivablesAging() (*models.AgingReport, error) {
+	return s.repo.GetReceivablesAging()
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type ShiftService struct {
+	repo *repositories.ShiftRepository
+}
+
+func NewShiftService(repo *repositories.ShiftRepository) *ShiftService {
+	return &ShiftService{repo: repo}
+}
+
+func (s *ShiftService) Open(req models.OpenShiftRequest) (*models.Shift, error) {
+	fieldErrors := make([]models.FieldError, 0)
+	if req.Cashier == "" {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "cashier", Message: "cashier is required"})
+	}
+	if req.OpeningFloat < 0 {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "opening_float", Message: "opening_float must not be negative"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid shift", Fields: fieldErrors}
+	}
+
+	return s.repo.Open(req)
+}
+
+// GetAll lists shifts with the given status; an empty status lists the
+// open ones.
+func (s *ShiftService) GetAll(status string) ([]models.Shift, error) {
+	if status == "" {
+		status = models.ShiftStatusOpen
+	}
+	if status != models.ShiftStatusOpen && status != models.ShiftStatusClosed {
+		return nil, &models.ValidationError{
+			Message: "invalid status",
+			Fields:  []models.FieldError{{Field: "status", Message: "status must be open or closed"}},
+		}
+	}
+
+	return s.repo.GetAll(status)
+}
+
+func (s *ShiftService) GetByID(id int) (*models.Shift, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *ShiftService) AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	fieldErrors := make([]models.FieldError, 0)
+	if req.Type != models.CashMovementIn && req.Type != models.CashMovementOut {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "type",
+			Message: fmt.Sprintf("type must be %q or %q", models.CashMovementIn, models.CashMovementOut),
+		})
+	}
+	if req.Amount <= 0 {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "amount", Message: "amount must be greater than 0"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid cash movement", Fields: fieldErrors}
+	}
+
+	return s.repo.AddCashMovement(shiftID, req)
+}
+
+// Close ends a shift. The counted cash is required; the response carries
+// the expected amount and the variance.
+func (s *ShiftService) Close(id int, req models.CloseShiftRequest) (*models.Shift, error) {
+	if req.CountedCash == nil {
+		return nil, &models.ValidationError{
+			Message: "invalid shift",
+			Fields:  []models.FieldError{{Field: "counted_cash", Message: "counted_cash is required"}},
+		}
+	}
+	if *req.CountedCash < 0 {
+		return nil, &models.ValidationError{
+			Message: "invalid shift",
+			Fields:  []models.FieldError{{Field: "counted_cash", Message: "counted_cash must not be negative"}},
+		}
+	}
+
+	return s.repo.Close(id, req)
+}
@@ -1,18 +1,507 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kasir-api/barcode"
 	"kasir-api/models"
+	"kasir-api/receipt"
 	"kasir-api/repositories"
+	"slices"
+	"strings"
+	"time"
 )
 
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again
+// with a different request body.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request body")
+
+// CheckoutLimits caps the quantities a single checkout may ask for. A zero
+// limit means no limit.
+type CheckoutLimits struct {
+	MaxItemQuantity        int
+	MaxTransactionQuantity int
+}
+
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo            *repositories.TransactionRepository
+	refundRepo      *repositories.RefundRepository
+	idempotencyRepo *repositories.IdempotencyRepository
+	idempotencyTTL  time.Duration
+	printer         *receipt.Printer
+	limits          CheckoutLimits
+}
+
+func NewTransactionService(repo *repositories.TransactionRepository, refundRepo *repositories.RefundRepository, idempotencyRepo *repositories.IdempotencyRepository, idempotencyTTL time.Duration, printer *receipt.Printer, limits CheckoutLimits) *TransactionService {
+	return &TransactionService{
+		repo:            repo,
+		refundRepo:      refundRepo,
+		idempotencyRepo: idempotencyRepo,
+		idempotencyTTL:  idempotencyTTL,
+		printer:         printer,
+		limits:          limits,
+	}
+}
+
+// Checkout creates a transaction. When idempotencyKey is set and a result is
+// already stored for it, that result is returned with replayed set to true
+// instead of creating a second transaction.
+func (s *TransactionService) Checkout(req models.CheckoutRequest, idempotencyKey string) (transaction *models.Transaction, replayed bool, err error) {
+	req.Items, err = s.normaliseItems(req.Items)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := validatePayments(req.Payments); err != nil {
+		return nil, false, err
+	}
+
+	if idempotencyKey == "" {
+		transaction, err = s.repo.CreateTransaction(req, nil)
+		return transaction, false, err
+	}
+
+	hash, err := hashCheckoutRequest(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	stored, err := s.replay(idempotencyKey, hash)
+	if err != nil || stored != nil {
+		return stored, stored != nil, err
+	}
+
+	transaction, err = s.repo.CreateTransaction(req, &models.IdempotencyKey{
+		Key:         idempotencyKey,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(s.idempotencyTTL),
+	})
+	if errors.Is(err, repositories.ErrIdempotencyKeyTaken) {
+		// a concurrent retry with the same key won the race
+		stored, err = s.replay(idempotencyKey, hash)
+		if err == nil && stored == nil {
+			err = repositories.ErrIdempotencyKeyTaken
+		}
+		return stored, stored != nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return transaction, false, nil
+}
+
+const (
+	maxSyncBatchSize = 500
+	// maxSyncClockSkew is how far ahead of the server a till's clock may be.
+	maxSyncClockSkew = 5 * time.Minute
+)
+
+// Sync records a batch of sales made while a till was offline. Each sale
+// goes through checkout on its own: a sale that cannot be accepted is
+// reported as rejected without holding up the rest, and a sale uploaded
+// again is reported as a duplicate. Other errors stop the batch; since
+// accepted sales are kept by client ID, the batch can simply be resent.
+func (s *TransactionService) Sync(req models.SyncRequest) (*models.SyncResponse, error) {
+	if len(req.Transactions) == 0 || len(req.Transactions) > maxSyncBatchSize {
+		return nil, &models.ValidationError{
+			Message: "invalid sync batch",
+			Fields: []models.FieldError{{
+				Field:   "transactions",
+				Message: fmt.Sprintf("between 1 and %d transactions are required", maxSyncBatchSize),
+			}},
+		}
+	}
+
+	res := &models.SyncResponse{Results: make([]models.SyncResult, 0, len(req.Transactions))}
+	for _, sale := range req.Transactions {
+		sale.ClientID = strings.ToLower(strings.TrimSpace(sale.ClientID))
+		result := models.SyncResult{ClientID: sale.ClientID}
+
+		transaction, duplicate, err := s.syncTransaction(sale)
+		var validationErr *models.ValidationError
+		var stockErr *models.InsufficientStockError
+		switch {
+		case errors.As(err, &validationErr):
+			result.Status = models.SyncStatusRejected
+			result.Reason = validationErr.Message
+			result.Fields = validationErr.Fields
+			res.Rejected++
+		case errors.As(err, &stockErr):
+			result.Status = models.SyncStatusRejected
+			result.Reason = stockErr.Message
+			result.Shortages = stockErr.Items
+			res.Rejected++
+		case err != nil:
+			return nil, err
+		case duplicate:
+			result.Status = models.SyncStatusDuplicate
+			res.Duplicates++
+		default:
+			result.Status = models.SyncStatusAccepted
+			res.Accepted++
+		}
+		if transaction != nil {
+			result.TransactionID = transaction.ID
+			result.InvoiceNumber = transaction.InvoiceNumber
+		}
+
+		res.Results = append(res.Results, result)
+	}
+
+	return res, nil
+}
+
+func (s *TransactionService) syncTransaction(sale models.SyncTransaction) (*models.Transaction, bool, error) {
+	fieldErrors := make([]models.FieldError, 0)
+	if !isUUID(sale.ClientID) {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "client_id", Message: "client_id must be a UUID"})
+	}
+	if sale.CreatedAt.IsZero() {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "created_at", Message: "created_at is required"})
+	} else if sale.CreatedAt.After(time.Now().Add(maxSyncClockSkew)) {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "created_at", Message: "created_at is in the future"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, false, &models.ValidationError{Message: "invalid transaction", Fields: fieldErrors}
+	}
+
+	var err error
+	sale.Items, err = s.normaliseItems(sale.Items)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := validatePayments(sale.Payments); err != nil {
+		return nil, false, err
+	}
+
+	return s.repo.Sync(sale)
+}
+
+// isUUID reports whether s is a UUID written in lower-case hex with dashes.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Quote prices a checkout without saving it. Payments are optional here;
+// when given they are checked the same way as in Checkout.
+func (s *TransactionService) Quote(req models.CheckoutRequest) (*models.CheckoutQuote, error) {
+	var err error
+	req.Items, err = s.normaliseItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Payments) > 0 {
+		if err := validatePayments(req.Payments); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Quote(req)
+}
+
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
+)
+
+func (s *TransactionService) GetAll(filter models.TransactionFilter) (*models.TransactionList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultTransactionPageSize
+	}
+	if filter.Limit > maxTransactionPageSize {
+		filter.Limit = maxTransactionPageSize
+	}
+
+	transactions, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransactionList{
+		Data:  transactions,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: total,
+	}, nil
+}
+
+func (s *TransactionService) GetByID(id int) (*models.Transaction, error) {
+	transaction, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.refundRepo.GetByTransactionIDs([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	transaction.Refunds = refunds[id]
+
+	return transaction, nil
+}
+
+// Receipt renders a stored transaction as a receipt in the given format
+// (receipt.FormatText or receipt.FormatESCPOS). A width of 0 uses the
+// configured default.
+func (s *TransactionService) Receipt(id int, format string, width int) ([]byte, error) {
+	transaction, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == receipt.FormatESCPOS {
+		return s.printer.ESCPOS(transaction, width), nil
+	}
+	return []byte(s.printer.Text(transaction, width)), nil
+}
+
+// Void cancels a whole transaction, returning all of its stock.
+func (s *TransactionService) Void(id int, req models.VoidRequest) (*models.Refund, error) {
+	return s.refundRepo.Create(id, models.RefundTypeVoid, req.Reason, nil, req.ShiftID, req.Cashier)
+}
+
+// Refund returns part of a transaction, line by line.
+func (s *TransactionService) Refund(id int, req models.RefundRequest) (*models.Refund, error) {
+	if len(req.Items) == 0 {
+		return nil, &models.ValidationError{
+			Message: "invalid refund items",
+			Fields:  []models.FieldError{{Field: "items", Message: "at least one item is required"}},
+		}
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	seen := make(map[int]bool)
+	for i, item := range req.Items {
+		if seen[item.TransactionDetailID] {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].transaction_detail_id", i),
+				Message: "detail is listed more than once",
+			})
+		}
+		seen[item.TransactionDetailID] = true
+
+		if item.Quantity <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: "quantity must be greater than 0",
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid refund items", Fields: fieldErrors}
+	}
+
+	return s.refundRepo.Create(id, models.RefundTypeRefund, req.Reason, req.Items, req.ShiftID, req.Cashier)
+}
+
+func (s *TransactionService) replay(key, hash string) (*models.Transaction, error) {
+	stored, err := s.idempotencyRepo.Get(key)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	if stored.RequestHash != hash {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	return stored.Response, nil
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+// normaliseItems checks the checkout items, looks up the products of
+// scanned barcodes and merges lines for the same product into the first of
+// them. Lines with their own discount are not merged, so a product with a
+// line discount may only be listed once.
+func (s *TransactionService) normaliseItems(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	if len(items) == 0 {
+		return nil, &models.ValidationError{
+			Message: "invalid items",
+			Fields:  []models.FieldError{{Field: "items", Message: "at least one item is required"}},
+		}
+	}
+
+	items, err := s.resolveBarcodes(items)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	merged := make([]models.CheckoutItem, 0, len(items))
+	first := make(map[int]int) // product id -> index in merged
+	for i, item := range items {
+		if item.ProductID <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].product_id", i),
+				Message: "product_id or barcode is required",
+			})
+			continue
+		}
+		if item.Quantity <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: "quantity must be greater than 0",
+			})
+			continue
+		}
+
+		j, seen := first[item.ProductID]
+		if !seen {
+			first[item.ProductID] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+		if item.Discount != nil || merged[j].Discount != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].product_id", i),
+				Message: "product is listed more than once and one of its lines has a discount; combine them into one line",
+			})
+			continue
+		}
+		merged[j].Quantity += item.Quantity
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid items", Fields: fieldErrors}
+	}
+
+	total := 0
+	for i, item := range merged {
+		total += item.Quantity
+		if s.limits.MaxItemQuantity > 0 && item.Quantity > s.limits.MaxItemQuantity {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: fmt.Sprintf("quantity of %d for product %d exceeds the maximum of %d", item.Quantity, item.ProductID, s.limits.MaxItemQuantity),
+			})
+		}
+	}
+	if s.limits.MaxTransactionQuantity > 0 && total > s.limits.MaxTransactionQuantity {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "items",
+			Message: fmt.Sprintf("total quantity of %d exceeds the maximum of %d per transaction", total, s.limits.MaxTransactionQuantity),
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid items", Fields: fieldErrors}
+	}
+
+	return merged, nil
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+// resolveBarcodes fills in the product ID of items given by barcode. The
+// barcode is normalised first, and must match the product ID when both are
+// given.
+func (s *TransactionService) resolveBarcodes(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	items = slices.Clone(items)
+
+	fieldErrors := make([]models.FieldError, 0)
+	codes := make([]string, 0)
+	for i, item := range items {
+		if item.Barcode == "" {
+			continue
+		}
+		code, err := barcode.Normalize(item.Barcode)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: fmt.Sprintf("items[%d].barcode", i), Message: err.Error()})
+			continue
+		}
+		items[i].Barcode = code
+		codes = append(codes, code)
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid items", Fields: fieldErrors}
+	}
+	if len(codes) == 0 {
+		return items, nil
+	}
+
+	ids, err := s.repo.ProductIDsByBarcode(codes)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if item.Barcode == "" {
+			continue
+		}
+		id, ok := ids[item.Barcode]
+		switch {
+		case !ok:
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].barcode", i),
+				Message: fmt.Sprintf("barcode %s not found", item.Barcode),
+			})
+		case item.ProductID != 0 && item.ProductID != id:
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].barcode", i),
+				Message: fmt.Sprintf("barcode %s belongs to product id %d, not %d", item.Barcode, id, item.ProductID),
+			})
+		default:
+			items[i].ProductID = id
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &models.ValidationError{Message: "invalid items", Fields: fieldErrors}
+	}
+
+	return items, nil
+}
+
+// validatePayments checks the shape of the payments. Whether they cover the
+// total is only known once prices are read, inside the checkout itself.
+func validatePayments(payments []models.CheckoutPayment) error {
+	if len(payments) == 0 {
+		return &models.ValidationError{
+			Message: "invalid payments",
+			Fields:  []models.FieldError{{Field: "payments", Message: "at least one payment is required"}},
+		}
+	}
+
+	fieldErrors := make([]models.FieldError, 0)
+	for i, payment := range payments {
+		if !slices.Contains(models.PaymentMethods, payment.Method) {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("payments[%d].method", i),
+				Message: fmt.Sprintf("method must be one of %s", strings.Join(models.PaymentMethods, ", ")),
+			})
+		}
+		if payment.Amount <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fmt.Sprintf("payments[%d].amount", i),
+				Message: "amount must be greater than 0",
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &models.ValidationError{Message: "invalid payments", Fields: fieldErrors}
+	}
+
+	return nil
+}
+
+func hashCheckoutRequest(req models.CheckoutRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
 }